@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/Redarcher9/Books-Management-System/config"
+	"github.com/Redarcher9/Books-Management-System/db"
+	"github.com/Redarcher9/Books-Management-System/internal/controller"
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/eventbus"
 	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/kafka"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
 	"github.com/Redarcher9/Books-Management-System/internal/routes"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
@@ -17,6 +26,15 @@ import (
 var envConfig = config.Init()
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	r := gin.Default()
 
 	// CORS configuration
@@ -24,17 +42,32 @@ func main() {
 		AllowOrigins:     []string{"*"}, // Update with specific origins in production
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Cache"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	r.Use(routes.RequestTimeout(envConfig.RequestTimeout))
 	//Instantiate Database,Kafka and Redis Server
-	dbInstance := setUpDatabase()
-	kafkaInstance := setUpKafkaProducer()
 	redisInstance := setUpRedis()
+	eventBus := setUpEventBus(redisInstance)
+	store := setUpBookStore(setUpCache(redisInstance))
+
+	//Instantiate Repository and Service through dependency injection
+	bookService := service.NewBookInteractor(store.books, envConfig.DuplicateThreshold)
+	deadLetterService := service.NewDeadLetterInteractor(store.deadLetters)
+	cacheService := service.NewCacheInteractor(bookService.Repo)
+
+	//Relay book events from the outbox to the event bus
+	outboxRelay := setUpOutboxRelay(store.outbox, eventBus)
+	go outboxRelay.Run(context.Background())
+
+	//Apply book commands from Kafka when Kafka is the event bus and a command topic is configured
+	if usesKafka() && envConfig.KafkaCommandTopic != "" {
+		go runBookCommandConsumer(context.Background(), bookService)
+	}
 
 	//Setup Routes and Swagger URLs
-	routes.SetupRoutes(r, dbInstance, kafkaInstance, redisInstance)
+	routes.SetupRoutes(r, bookService, outboxRelay, deadLetterService, cacheService)
 	routes.SetupSwagger(r)
 
 	//Run the Gin server on specified port
@@ -48,25 +81,218 @@ func setUpDatabase() *gorm.DB {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
-	}), &gorm.Config{})
+	}), &gorm.Config{
+		// Report constraint violations as gorm errors such as gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
 	if err != nil {
 		panic(fmt.Errorf("failed to connect to database %w", err))
 	}
 	return db
 }
 
+// Refuses to serve unless the database schema is at the version of the embedded migrations
+func checkSchemaVersion(gormDB *gorm.DB) {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		panic(fmt.Errorf("failed to get database connection %w", err))
+	}
+	if err := db.CheckVersion(sqlDB); err != nil {
+		panic(fmt.Errorf("failed to check database schema %w", err))
+	}
+}
+
+// Reports whether Kafka is the configured event bus
+func usesKafka() bool {
+	return envConfig.EventBusBackend == eventbus.KafkaBackend || envConfig.EventBusBackend == ""
+}
+
+// Sets up the configured event bus backend and Returns event bus Instance
+func setUpEventBus(redisDB *redis.Client) service.EventBus {
+	switch envConfig.EventBusBackend {
+	case eventbus.KafkaBackend, "":
+		return setUpKafkaProducer()
+	case eventbus.RedisBackend:
+		return eventbus.NewRedisStreams(redisDB, envConfig.EventBusRedisMaxLen)
+	case eventbus.MemoryBackend:
+		bus := eventbus.NewChannel(envConfig.EventBusBufferSize)
+		go logEvents(bus)
+		return bus
+	default:
+		panic(fmt.Errorf("unknown event bus backend %q", envConfig.EventBusBackend))
+	}
+}
+
+// Prints events published on the in-process bus, which have no other consumer
+func logEvents(bus *eventbus.Channel) {
+	events, _ := bus.Subscribe("")
+	for event := range events {
+		fmt.Printf("event published to %s: %s\n", event.Topic, event.Value)
+	}
+}
+
 // Sets up kafka and Returns kafka Instance
 func setUpKafkaProducer() *kafka.KafkaProducer {
-	return kafka.NewKafkaProducer(envConfig.KafkaAddress, envConfig.KafkaTopic)
+	producer, err := kafka.NewKafkaProducer(kafka.ProducerConfig{
+		BrokerAddress: envConfig.KafkaAddress,
+		DefaultTopic:  envConfig.KafkaTopic,
+		EventTopics:   bookEventTopics(),
+		BatchSize:     envConfig.KafkaBatchSize,
+		BatchTimeout:  envConfig.KafkaBatchTimeout,
+		Compression:   envConfig.KafkaCompression,
+		RequiredAcks:  envConfig.KafkaRequiredAcks,
+		Security:      kafkaSecurity(),
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to set up kafka producer %w", err))
+	}
+	return producer
+}
+
+// Returns the topic each book event type is routed to, empty when it goes to KAFKA_TOPIC
+func bookEventTopics() map[string]string {
+	return map[string]string{
+		domain.BookCreatedEventType: envConfig.KafkaBookCreatedTopic,
+		domain.BookUpdatedEventType: envConfig.KafkaBookUpdatedTopic,
+		domain.BookDeletedEventType: envConfig.KafkaBookDeletedTopic,
+		domain.BookMergedEventType:  envConfig.KafkaBookMergedTopic,
+	}
+}
+
+func kafkaSecurity() kafka.SecurityConfig {
+	return kafka.SecurityConfig{
+		TLSEnabled:            envConfig.KafkaTLSEnabled,
+		TLSInsecureSkipVerify: envConfig.KafkaTLSInsecureSkipVerify,
+		SASLMechanism:         envConfig.KafkaSASLMechanism,
+		SASLUsername:          envConfig.KafkaSASLUsername,
+		SASLPassword:          envConfig.KafkaSASLPassword,
+	}
+}
+
+// bookStore holds the repositories of the configured BOOK_REPOSITORY, which share one database
+// so that book changes and their outbox messages are written together
+type bookStore struct {
+	books       service.BookRepo
+	outbox      service.OutboxRepo
+	deadLetters service.DeadLetterRepo
+}
+
+// Sets up the configured book repository and Returns its repositories
+func setUpBookStore(bookCache repository.Cache) bookStore {
+	switch envConfig.BookRepository {
+	case "postgres", "":
+		dbInstance := setUpDatabase()
+		checkSchemaVersion(dbInstance)
+		return newGormBookStore(dbInstance, setUpBooksRepo(dbInstance, bookCache))
+	case "eventsourced":
+		dbInstance := setUpDatabase()
+		checkSchemaVersion(dbInstance)
+		return newGormBookStore(dbInstance, repository.NewEventSourcedBooksRepo(dbInstance))
+	case "sqlite":
+		dbInstance := setUpSQLite()
+		return newGormBookStore(dbInstance, setUpBooksRepo(dbInstance, bookCache))
+	case "memory":
+		outbox := repository.NewMemoryOutbox()
+		return bookStore{
+			books:       repository.NewMemoryBooksRepo(outbox),
+			outbox:      outbox,
+			deadLetters: outbox,
+		}
+	default:
+		panic(fmt.Errorf("unknown book repository %q", envConfig.BookRepository))
+	}
+}
+
+func newGormBookStore(dbInstance *gorm.DB, books service.BookRepo) bookStore {
+	return bookStore{
+		books:       books,
+		outbox:      repository.NewOutboxRepo(dbInstance),
+		deadLetters: repository.NewDeadLettersRepo(dbInstance),
+	}
+}
+
+// Sets up the cached books repository on a Postgres or SQLite database
+func setUpBooksRepo(dbInstance *gorm.DB, bookCache repository.Cache) *repository.Books {
+	// The in-process tier and its invalidations only pay off in front of the shared Redis cache
+	localCacheSize := envConfig.LocalCacheSize
+	if envConfig.CacheBackend != cache.RedisBackend && envConfig.CacheBackend != "" {
+		localCacheSize = 0
+	}
+
+	booksRepo := repository.NewBooksRepo(dbInstance, bookCache, repository.CacheConfig{
+		TTL:         envConfig.CacheTTL,
+		StaleTTL:    envConfig.CacheStaleTTL,
+		LocalSize:   localCacheSize,
+		LocalTTL:    envConfig.LocalCacheTTL,
+		NegativeTTL: envConfig.CacheNegativeTTL,
+	})
+	go booksRepo.ListenForInvalidations(context.Background())
+	return booksRepo
+}
+
+// Sets up the SQLite database and Returns Database Instance
+func setUpSQLite() *gorm.DB {
+	dbInstance, err := repository.OpenSQLite(envConfig.SQLitePath)
+	if err != nil {
+		panic(fmt.Errorf("failed to open sqlite database %w", err))
+	}
+	return dbInstance
+}
+
+// Consumes book commands until ctx is cancelled, restarting the consumer after failures
+func runBookCommandConsumer(ctx context.Context, bookService *service.BookInteractor) {
+	handler := controller.NewBookCommandHandler(bookService)
+	for ctx.Err() == nil {
+		consumer, err := kafka.NewKafkaConsumer(envConfig.KafkaAddress, envConfig.KafkaCommandTopic, envConfig.KafkaCommandGroupID, envConfig.KafkaDeadLetterTopic, kafkaSecurity())
+		if err != nil {
+			panic(fmt.Errorf("failed to set up kafka consumer %w", err))
+		}
+		if err := consumer.Run(ctx, handler.Handle); err != nil {
+			fmt.Println("book command consumer failed:", err)
+		}
+		consumer.Close()
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// Sets up the outbox relay and Returns outbox relay Instance
+func setUpOutboxRelay(outbox service.OutboxRepo, eventBus service.EventBus) *service.OutboxRelay {
+	retryPolicy := domain.RetryPolicy{
+		MaxAttempts: envConfig.OutboxMaxAttempts,
+		BaseDelay:   envConfig.OutboxRetryBaseDelay,
+		MaxDelay:    envConfig.OutboxRetryMaxDelay,
+	}
+	return service.NewOutboxRelay(outbox, eventBus, envConfig.OutboxPollInterval, envConfig.OutboxBatchSize, retryPolicy)
 }
 
 // Sets up Redis and Returns Redis Instance
 func setUpRedis() *redis.Client {
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     envConfig.RedisAddress,
-		Password: envConfig.RedisPassword,
-		DB:       envConfig.RedisDB,
+		Addr:         envConfig.RedisAddress,
+		Password:     envConfig.RedisPassword,
+		DB:           envConfig.RedisDB,
+		DialTimeout:  envConfig.RedisTimeout,
+		ReadTimeout:  envConfig.RedisTimeout,
+		WriteTimeout: envConfig.RedisTimeout,
 	})
 
+	// Redis being down is not fatal, the cache circuit keeps it out of the request path
+	if err := rdb.Ping().Err(); err != nil {
+		fmt.Println("redis is unreachable:", err)
+	}
 	return rdb
 }
+
+// Sets up the configured cache backend and Returns cache Instance
+func setUpCache(redisDB *redis.Client) repository.Cache {
+	switch envConfig.CacheBackend {
+	case cache.RedisBackend, "":
+		return cache.NewBreaker(cache.NewRedis(redisDB), envConfig.CacheBreakerThreshold, envConfig.CacheBreakerCooldown)
+	case cache.MemoryBackend:
+		return cache.NewMemory(envConfig.CacheMemorySize)
+	case cache.NoOpBackend:
+		return cache.NoOp{}
+	default:
+		panic(fmt.Errorf("unknown cache backend %q", envConfig.CacheBackend))
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Redarcher9/Books-Management-System/db"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up [n]         apply all pending migrations, or the next n, then backfill normalized titles and authors
+  down [n|all]   roll back the last n migrations (default 1), or all of them
+  status         print the current and expected schema versions
+  force <v>      record version v as applied and clean, after fixing a failed migration by hand`
+
+// runMigrate applies the migrations embedded in the binary to the configured database, e.g.
+//
+//	migrate up
+//	migrate down 1
+//	migrate force 7
+func runMigrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, migrateUsage) }
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if flags.Arg(0) == "status" {
+		printMigrationStatus()
+		return
+	}
+
+	sqlDB, err := setUpDatabase().DB()
+	if err != nil {
+		panic(fmt.Errorf("failed to get database connection %w", err))
+	}
+	migrator, err := db.NewMigrator(sqlDB)
+	if err != nil {
+		panic(fmt.Errorf("failed to set up migrations %w", err))
+	}
+
+	switch flags.Arg(0) {
+	case "up":
+		if flags.NArg() > 1 {
+			err = migrator.Steps(migrationSteps(flags.Arg(1)))
+		} else {
+			err = migrator.Up()
+		}
+	case "down":
+		switch flags.Arg(1) {
+		case "all":
+			err = migrator.Down()
+		case "":
+			err = migrator.Steps(-1)
+		default:
+			err = migrator.Steps(-migrationSteps(flags.Arg(1)))
+		}
+	case "force":
+		version, convErr := strconv.Atoi(flags.Arg(1))
+		if convErr != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", flags.Arg(1))
+			os.Exit(2)
+		}
+		err = migrator.Force(version)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// Closing the migrator also closes sqlDB, so the status is read on a new connection
+	migrator.Close()
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("no change")
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %s\n", flags.Arg(0), err)
+		os.Exit(1)
+	}
+	if flags.Arg(0) == "up" {
+		backfillNormalized()
+	}
+	printMigrationStatus()
+}
+
+// backfillNormalized recomputes the normalized titles and authors the migrations filled in SQL,
+// so they match the ones the application writes
+func backfillNormalized() {
+	gormDB := setUpDatabase()
+	if sqlDB, err := gormDB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	updated, err := repository.BackfillNormalized(context.Background(), gormDB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backfill of normalized titles and authors failed: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("normalized the title and author of %d rows\n", updated)
+}
+
+// migrationSteps parses a positive number of migrations
+func migrationSteps(arg string) int {
+	steps, err := strconv.Atoi(arg)
+	if err != nil || steps <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid number of migrations %q\n", arg)
+		os.Exit(2)
+	}
+	return steps
+}
+
+func printMigrationStatus() {
+	sqlDB, err := setUpDatabase().DB()
+	if err != nil {
+		panic(fmt.Errorf("failed to get database connection %w", err))
+	}
+	defer sqlDB.Close()
+
+	expected, err := db.ExpectedVersion()
+	if err != nil {
+		panic(fmt.Errorf("failed to read embedded migrations %w", err))
+	}
+	current, dirty, err := db.CurrentVersion(sqlDB)
+	if err != nil {
+		panic(fmt.Errorf("failed to read schema version %w", err))
+	}
+	fmt.Printf("schema version %d (dirty: %t), expected %d\n", current, dirty, expected)
+}
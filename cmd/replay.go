@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/kafka"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"gorm.io/gorm"
+)
+
+// Projections that can be rebuilt with the replay command
+var projections = map[string]func(db *gorm.DB) service.Projection{
+	repository.BookSearchProjectionName: func(db *gorm.DB) service.Projection {
+		return repository.NewBookSearchProjection(db)
+	},
+}
+
+// replayTopics returns KAFKA_TOPIC and every topic a book event type is routed to
+func replayTopics() []string {
+	topics := []string{envConfig.KafkaTopic}
+	for _, topic := range bookEventTopics() {
+		if topic != "" && !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	slices.Sort(topics[1:])
+	return topics
+}
+
+// runReplay replays book events into a projection, e.g.
+//
+//	replay -projection book_search -reset
+//	replay -projection book_search -from-time 2025-01-01T00:00:00Z
+func runReplay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	projectionName := flags.String("projection", repository.BookSearchProjectionName, "projection to feed")
+	topics := flags.String("topics", strings.Join(replayTopics(), ","), "comma separated topics to read book events from")
+	fromOffset := flags.Int64("from-offset", -1, "offset to start every partition at (default: resume from checkpoint)")
+	fromTime := flags.String("from-time", "", "RFC3339 timestamp to start every partition at (default: resume from checkpoint)")
+	reset := flags.Bool("reset", false, "clear the projection and its checkpoints before replaying")
+	flags.Parse(args)
+
+	newProjection, ok := projections[*projectionName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown projection %q\n", *projectionName)
+		os.Exit(2)
+	}
+
+	var from domain.ReplayFrom
+	if *fromOffset >= 0 {
+		from.Offset = fromOffset
+	}
+	if *fromTime != "" {
+		t, err := time.Parse(time.RFC3339, *fromTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -from-time: %s\n", err)
+			os.Exit(2)
+		}
+		from.Time = &t
+	}
+
+	stream, err := kafka.NewKafkaEventStream(envConfig.KafkaAddress, kafkaSecurity())
+	if err != nil {
+		panic(fmt.Errorf("failed to set up kafka event stream %w", err))
+	}
+	db := setUpDatabase()
+	// Projections write to tables of the current schema, so a stale one must be migrated first
+	checkSchemaVersion(db)
+	replayer := service.NewProjectionReplayer(stream, repository.NewCheckpointsRepo(db))
+
+	applied, err := replayer.Replay(context.Background(), newProjection(db), strings.Split(*topics, ","), from, *reset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay failed after %d events: %s\n", applied, err)
+		os.Exit(1)
+	}
+	fmt.Printf("replayed %d events into %s\n", applied, *projectionName)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -19,6 +20,59 @@ type Config struct {
 	RedisAddress  string `mapstructure:"REDIS_ADDRESS"`
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
 	RedisDB       int    `mapstructure:"REDIS_DB"`
+
+	RequestTimeout time.Duration `mapstructure:"REQUEST_TIMEOUT"`
+
+	RedisTimeout time.Duration `mapstructure:"REDIS_TIMEOUT"`
+
+	CacheBackend          string        `mapstructure:"CACHE_BACKEND"`
+	CacheMemorySize       int           `mapstructure:"CACHE_MEMORY_SIZE"`
+	CacheBreakerThreshold int           `mapstructure:"CACHE_BREAKER_THRESHOLD"`
+	CacheBreakerCooldown  time.Duration `mapstructure:"CACHE_BREAKER_COOLDOWN"`
+
+	CacheTTL      time.Duration `mapstructure:"CACHE_TTL"`
+	CacheStaleTTL time.Duration `mapstructure:"CACHE_STALE_TTL"`
+
+	CacheNegativeTTL time.Duration `mapstructure:"CACHE_NEGATIVE_TTL"`
+
+	LocalCacheSize int           `mapstructure:"LOCAL_CACHE_SIZE"`
+	LocalCacheTTL  time.Duration `mapstructure:"LOCAL_CACHE_TTL"`
+
+	BookRepository string `mapstructure:"BOOK_REPOSITORY"`
+	SQLitePath     string `mapstructure:"SQLITE_PATH"`
+
+	DuplicateThreshold float64 `mapstructure:"DUPLICATE_THRESHOLD"`
+
+	EventBusBackend     string `mapstructure:"EVENT_BUS_BACKEND"`
+	EventBusRedisMaxLen int64  `mapstructure:"EVENT_BUS_REDIS_MAXLEN"`
+	EventBusBufferSize  int    `mapstructure:"EVENT_BUS_BUFFER_SIZE"`
+
+	KafkaBookCreatedTopic string `mapstructure:"KAFKA_BOOK_CREATED_TOPIC"`
+	KafkaBookUpdatedTopic string `mapstructure:"KAFKA_BOOK_UPDATED_TOPIC"`
+	KafkaBookDeletedTopic string `mapstructure:"KAFKA_BOOK_DELETED_TOPIC"`
+	KafkaBookMergedTopic  string `mapstructure:"KAFKA_BOOK_MERGED_TOPIC"`
+
+	KafkaBatchSize    int           `mapstructure:"KAFKA_BATCH_SIZE"`
+	KafkaBatchTimeout time.Duration `mapstructure:"KAFKA_BATCH_TIMEOUT"`
+	KafkaCompression  string        `mapstructure:"KAFKA_COMPRESSION"`
+	KafkaRequiredAcks string        `mapstructure:"KAFKA_REQUIRED_ACKS"`
+
+	KafkaTLSEnabled            bool   `mapstructure:"KAFKA_TLS_ENABLED"`
+	KafkaTLSInsecureSkipVerify bool   `mapstructure:"KAFKA_TLS_INSECURE_SKIP_VERIFY"`
+	KafkaSASLMechanism         string `mapstructure:"KAFKA_SASL_MECHANISM"`
+	KafkaSASLUsername          string `mapstructure:"KAFKA_SASL_USERNAME"`
+	KafkaSASLPassword          string `mapstructure:"KAFKA_SASL_PASSWORD"`
+
+	KafkaCommandTopic    string `mapstructure:"KAFKA_COMMAND_TOPIC"`
+	KafkaCommandGroupID  string `mapstructure:"KAFKA_COMMAND_GROUP_ID"`
+	KafkaDeadLetterTopic string `mapstructure:"KAFKA_DEAD_LETTER_TOPIC"`
+
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+
+	OutboxMaxAttempts    int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxRetryBaseDelay time.Duration `mapstructure:"OUTBOX_RETRY_BASE_DELAY"`
+	OutboxRetryMaxDelay  time.Duration `mapstructure:"OUTBOX_RETRY_MAX_DELAY"`
 }
 
 func Init() *Config {
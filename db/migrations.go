@@ -0,0 +1,105 @@
+// Package db embeds the SQL migrations in db/migrations, and the SQLite schema, so the binary can apply them itself
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// SQLiteSchema creates the tables used with a SQLite database when they do not exist yet
+//
+//go:embed sqlite.sql
+var SQLiteSchema string
+
+// MigrationsTable is where golang-migrate records the schema version
+const MigrationsTable = "schema_migrations"
+
+// ErrSchemaVersion is returned when the database is not at the schema version the code expects
+var ErrSchemaVersion = errors.New("database schema version mismatch")
+
+// NewMigrator returns a migrator applying the embedded migrations to sqlDB.
+// Closing the migrator closes sqlDB.
+func NewMigrator(sqlDB *sql.DB) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	driver, err := pgxmigrate.WithInstance(sqlDB, &pgxmigrate.Config{MigrationsTable: MigrationsTable})
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewWithInstance("iofs", source, "pgx", driver)
+}
+
+// ExpectedVersion is the version of the newest embedded migration
+func ExpectedVersion() (uint, error) {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// CurrentVersion reads the schema version recorded in sqlDB, which is 0 before any migration ran
+func CurrentVersion(sqlDB *sql.DB) (version uint, dirty bool, err error) {
+	var exists bool
+	if err := sqlDB.QueryRow("SELECT to_regclass($1) IS NOT NULL", MigrationsTable).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	err = sqlDB.QueryRow(fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", MigrationsTable)).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// CheckVersion returns ErrSchemaVersion unless sqlDB is cleanly migrated to ExpectedVersion
+func CheckVersion(sqlDB *sql.DB) error {
+	expected, err := ExpectedVersion()
+	if err != nil {
+		return err
+	}
+	current, dirty, err := CurrentVersion(sqlDB)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d is dirty, fix it and run migrate force", ErrSchemaVersion, current)
+	}
+	if current < expected {
+		return fmt.Errorf("%w: database is at version %d, expected %d, run migrate up", ErrSchemaVersion, current, expected)
+	}
+	if current > expected {
+		return fmt.Errorf("%w: database is at version %d, newer than the expected %d", ErrSchemaVersion, current, expected)
+	}
+	return nil
+}
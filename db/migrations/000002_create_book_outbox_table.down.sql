@@ -0,0 +1 @@
+DROP TABLE IF EXISTS book_outbox;
\ No newline at end of file
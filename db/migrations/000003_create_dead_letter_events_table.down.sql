@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS dead_letter_events;
+
+ALTER TABLE book_outbox DROP COLUMN IF EXISTS next_attempt_at;
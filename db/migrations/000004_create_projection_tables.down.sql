@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS book_search;
+
+DROP TABLE IF EXISTS projection_checkpoints;
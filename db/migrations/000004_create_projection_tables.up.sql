@@ -0,0 +1,18 @@
+CREATE TABLE projection_checkpoints (
+    projection VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    partition INTEGER NOT NULL,
+    next_offset BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (projection, topic, partition)
+);
+
+CREATE TABLE book_search (
+    book_id INTEGER PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    year INTEGER NOT NULL,
+    search_text TEXT NOT NULL,
+    last_event_id VARCHAR(64) NOT NULL,
+    last_event_time TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS book_snapshots;
+
+DROP TABLE IF EXISTS book_event_store;
+
+DROP SEQUENCE IF EXISTS book_event_store_book_id_seq;
@@ -0,0 +1,23 @@
+CREATE SEQUENCE book_event_store_book_id_seq;
+
+CREATE TABLE book_event_store (
+    id BIGSERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (book_id, version)
+);
+
+CREATE INDEX book_event_store_occurred_at_idx ON book_event_store (book_id, occurred_at);
+
+CREATE TABLE book_snapshots (
+    book_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (book_id, version)
+);
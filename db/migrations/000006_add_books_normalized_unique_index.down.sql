@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS books_title_author_normalized_key;
+
+ALTER TABLE books DROP COLUMN IF EXISTS author_normalized;
+ALTER TABLE books DROP COLUMN IF EXISTS title_normalized;
@@ -0,0 +1,14 @@
+-- Normalized copies of title and author, kept in sync by the application:
+-- Unicode NFC, lower case, trimmed and with runs of whitespace collapsed to one space
+ALTER TABLE books ADD COLUMN title_normalized TEXT;
+ALTER TABLE books ADD COLUMN author_normalized TEXT;
+
+UPDATE books SET
+    title_normalized = lower(btrim(regexp_replace(normalize(title, NFC), '\s+', ' ', 'g'))),
+    author_normalized = lower(btrim(regexp_replace(normalize(author, NFC), '\s+', ' ', 'g')));
+
+ALTER TABLE books ALTER COLUMN title_normalized SET NOT NULL;
+ALTER TABLE books ALTER COLUMN author_normalized SET NOT NULL;
+
+-- Fails if duplicates already exist; they have to be merged or removed first
+CREATE UNIQUE INDEX books_title_author_normalized_key ON books (title_normalized, author_normalized);
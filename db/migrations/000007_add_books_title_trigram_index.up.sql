@@ -0,0 +1,4 @@
+-- Lets likely duplicate books be found by title similarity
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX books_title_normalized_trgm_idx ON books USING GIN (title_normalized gin_trgm_ops);
@@ -0,0 +1,7 @@
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS books_title_author_normalized_key;
+CREATE UNIQUE INDEX books_title_author_normalized_key ON books (title_normalized, author_normalized);
+
+ALTER TABLE books DROP COLUMN IF EXISTS merged_into;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
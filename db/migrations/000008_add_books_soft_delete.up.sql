@@ -0,0 +1,7 @@
+-- Books merged into another book are kept, soft deleted, pointing at the book they were merged into
+ALTER TABLE books ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE books ADD COLUMN merged_into INTEGER REFERENCES books (id) ON DELETE SET NULL;
+
+-- Soft deleted books must not block their title and author
+DROP INDEX IF EXISTS books_title_author_normalized_key;
+CREATE UNIQUE INDEX books_title_author_normalized_key ON books (title_normalized, author_normalized) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Serve the author and year filters of GET /books. Title substrings use the trigram index.
+CREATE INDEX books_author_normalized_idx ON books (author_normalized);
+CREATE INDEX books_year_idx ON books (year);
@@ -0,0 +1,2 @@
+-- Lets GET /books/suggest complete authors with typos, like titles
+CREATE INDEX books_author_normalized_trgm_idx ON books USING GIN (author_normalized gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS dead_letter_events_unresolved_idx;
+ALTER TABLE dead_letter_events DROP COLUMN IF EXISTS discarded_at;
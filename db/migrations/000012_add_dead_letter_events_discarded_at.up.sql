@@ -0,0 +1,5 @@
+ALTER TABLE dead_letter_events ADD COLUMN discarded_at TIMESTAMPTZ;
+
+-- Finds the unresolved dead letters that hold back later events for a book
+CREATE INDEX dead_letter_events_unresolved_idx ON dead_letter_events (aggregate_id, outbox_id)
+    WHERE replayed_at IS NULL AND discarded_at IS NULL;
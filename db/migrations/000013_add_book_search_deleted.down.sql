@@ -0,0 +1,2 @@
+DELETE FROM book_search WHERE deleted;
+ALTER TABLE book_search DROP COLUMN IF EXISTS deleted;
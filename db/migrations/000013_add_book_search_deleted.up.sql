@@ -0,0 +1,3 @@
+-- Deleted books stay as tombstones, so an older event replayed later cannot bring them back.
+-- Readers of book_search must filter on NOT deleted.
+ALTER TABLE book_search ADD COLUMN deleted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,30 @@
+-- Current state of every live event-sourced book, written in the same transaction as its events.
+-- Lists read it instead of rebuilding each book, and its unique index rejects duplicate books.
+CREATE TABLE book_event_state (
+    book_id INTEGER PRIMARY KEY,
+    version INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    year INTEGER NOT NULL,
+    title_normalized TEXT NOT NULL,
+    author_normalized TEXT NOT NULL
+);
+
+-- The latest event of a live book carries its state in data.after
+INSERT INTO book_event_state (book_id, version, title, author, year, title_normalized, author_normalized)
+SELECT book_id, version,
+    payload->'data'->'after'->>'title',
+    payload->'data'->'after'->>'author',
+    (payload->'data'->'after'->>'year')::int,
+    lower(btrim(regexp_replace(normalize(payload->'data'->'after'->>'title', NFC), '\s+', ' ', 'g'))),
+    lower(btrim(regexp_replace(normalize(payload->'data'->'after'->>'author', NFC), '\s+', ' ', 'g')))
+FROM (
+    SELECT DISTINCT ON (book_id) book_id, version, event_type, payload
+    FROM book_event_store
+    ORDER BY book_id, version DESC
+) latest
+WHERE event_type <> 'com.books-management-system.book.deleted'
+    AND NOT (event_type = 'com.books-management-system.book.merged' AND (payload->'data'->'source'->>'id')::int = book_id);
+
+-- Fails if duplicates already exist; they have to be merged or removed first
+CREATE UNIQUE INDEX book_event_state_title_author_normalized_key ON book_event_state (title_normalized, author_normalized);
@@ -0,0 +1,48 @@
+-- Schema of the SQLite database used with BOOK_REPOSITORY=sqlite, matching the Postgres
+-- migrations for the tables that mode uses. It is applied on every start, so it must stay idempotent.
+CREATE TABLE IF NOT EXISTS books (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    year INTEGER NOT NULL,
+    title_normalized TEXT NOT NULL,
+    author_normalized TEXT NOT NULL,
+    deleted_at DATETIME,
+    merged_into INTEGER REFERENCES books (id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS books_title_author_normalized_key ON books (title_normalized, author_normalized) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS books_author_normalized_idx ON books (author_normalized);
+CREATE INDEX IF NOT EXISTS books_year_idx ON books (year);
+
+CREATE TABLE IF NOT EXISTS book_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    aggregate_id INTEGER NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at DATETIME NOT NULL,
+    published_at DATETIME,
+    next_attempt_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS book_outbox_pending_idx ON book_outbox (id) WHERE published_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    outbox_id INTEGER NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    dead_lettered_at DATETIME NOT NULL,
+    replayed_at DATETIME,
+    discarded_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS dead_letter_events_aggregate_id_idx ON dead_letter_events (aggregate_id);
+CREATE INDEX IF NOT EXISTS dead_letter_events_unresolved_idx ON dead_letter_events (aggregate_id, outbox_id)
+    WHERE replayed_at IS NULL AND discarded_at IS NULL;
@@ -15,19 +15,170 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/books": {
+        "/admin/cache": {
             "get": {
-                "description": "Retrieve all books with pagination. If the provided offset or limit is less than 0, default values of limit = 10 and offset = 0 will be applied automatically.",
-                "consumes": [
+                "description": "Count the keys of the book cache keyspace and report the current book list version.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Inspect the book cache",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CacheStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "The configured repository has no cache"
+                    },
+                    "503": {
+                        "description": "Book cache is unavailable"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete every key of the book cache keyspace and clear the in-process cache of every instance.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "admin"
                 ],
-                "summary": "Get all books with pagination",
+                "summary": "Flush the book cache",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CacheFlushResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "The configured repository has no cache"
+                    },
+                    "503": {
+                        "description": "Book cache is unavailable"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/cache/books/{id}": {
+            "get": {
+                "description": "Show whether a book is cached, cached as missing, or stale.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Inspect the cache entry of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CachedBook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "The configured repository has no cache"
+                    },
+                    "503": {
+                        "description": "Book cache is unavailable"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/cache/warm": {
+            "post": {
+                "description": "Load up to count books from the database, caching them one by one and as book list pages of page_size books.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Warm the book cache",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of books to load",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books per cached list page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CacheWarmResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "The configured repository has no cache"
+                    },
+                    "503": {
+                        "description": "Book cache is unavailable"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters": {
+            "get": {
+                "description": "List book events that ran out of publish attempts, most recent first. If the provided offset or limit is less than 0, default values of limit = 10 and offset = 0 will be applied automatically.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List dead-lettered events",
                 "parameters": [
                     {
                         "type": "integer",
@@ -50,20 +201,288 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/domain.Book"
-                                }
+                                "$ref": "#/definitions/domain.DeadLetter"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters/{id}": {
+            "get": {
+                "description": "Inspect a dead-lettered event, including its payload and the last publish error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a dead-lettered event by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeadLetter"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format"
+                    },
+                    "404": {
+                        "description": "Dead letter not found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters/{id}/discard": {
+            "post": {
+                "description": "Give up on a dead-lettered event, so the later events for the same book that were held back behind it are published.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Discard a dead-lettered event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letter discarded"
+                    },
+                    "400": {
+                        "description": "Invalid ID format"
+                    },
+                    "404": {
+                        "description": "Dead letter not found"
+                    },
+                    "409": {
+                        "description": "Dead letter was already replayed or discarded"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters/{id}/replay": {
+            "post": {
+                "description": "Put a dead-lettered event back into the outbox so it is published again, ahead of the later events for the same book that were held back behind it.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay a dead-lettered event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Dead letter queued for replay"
+                    },
+                    "400": {
+                        "description": "Invalid ID format"
+                    },
+                    "404": {
+                        "description": "Dead letter not found"
+                    },
+                    "409": {
+                        "description": "Dead letter was already replayed or discarded"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/admin/outbox": {
+            "get": {
+                "description": "Report how many book events are waiting to be published, how far behind the relay is and its most recent failure.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get outbox relay statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OutboxStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/books": {
+            "get": {
+                "description": "Retrieve a page of books, optionally filtered and sorted. Pages are linked by opaque cursors, returned in the body and in an RFC 8288 Link header. If the provided limit is less than 1, the default of 10 will be applied automatically. Titles and authors are matched and sorted ignoring case and spacing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get all books with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor or prev_cursor of a page with the same filters and sort",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Count every book matching the filters",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "J.R.R. Tolkien",
+                        "description": "Only books by this author",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "hobbit",
+                        "description": "Only books whose title contains this text",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1900,
+                        "description": "Only books published in or after this year",
+                        "name": "year_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1999,
+                        "description": "Only books published in or before this year",
+                        "name": "year_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2,3",
+                        "description": "Only books with these comma separated IDs, at most 100",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "example": "-year,title",
+                        "description": "Comma separated fields to sort by (id, title, author, year), each descending when prefixed with -",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "first, prev and next pages"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid filter, sort, cursor or total, or the removed offset parameter"
+                    },
                     "500": {
                         "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             },
             "post": {
-                "description": "Create a new book with title, author, and year",
+                "description": "Create a new book with title, author, and year.\nUnless force is true, the book is rejected when it looks like an existing book, for example \"Hobbit, The\" by \"Tolkien, J. R. R.\" for \"The Hobbit\" by \"J.R.R. Tolkien\", and the likely duplicates are listed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -83,6 +502,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/domain.BookRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Create the book even if it looks like an existing book",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -90,20 +516,253 @@ const docTemplate = `{
                         "description": "Book Created Successfully"
                     },
                     "400": {
-                        "description": "Validation Error"
+                        "description": "Validation Error, or force is neither true nor false"
                     },
                     "409": {
-                        "description": "Book with provided Title and Author already exists"
+                        "description": "Book with provided Title and Author already exists, or likely duplicates were found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DuplicateResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/books/facets": {
+            "get": {
+                "description": "Count the books matching the same filters as the book list by author, most books first, and by decade and year, oldest first, for filters in a sidebar. Authors are counted ignoring case and spacing. Author and year are the only categorical fields of a book, so there are no other facets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Count books by author, decade and year",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Authors to count",
+                        "name": "authors",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "J.R.R. Tolkien",
+                        "description": "Only books by this author",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "hobbit",
+                        "description": "Only books whose title contains this text",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1900,
+                        "description": "Only books published in or after this year",
+                        "name": "year_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1999,
+                        "description": "Only books published in or before this year",
+                        "name": "year_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1,2,3",
+                        "description": "Only books with these comma separated IDs, at most 100",
+                        "name": "ids",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookFacets"
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or authors"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/books/search": {
+            "get": {
+                "description": "Full-text search of book titles and authors, most relevant books first. Every word of q must match, words in the title rank higher than words in the author, and the matching words are highlighted with \u003cmark\u003e elements in the HTML escaped title_highlight and author_highlight. Pages are linked by opaque cursors like the book list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Search books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "tolkien hobbit",
+                        "description": "Words to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor or prev_cursor of a page with the same q",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Count every matching book",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookSearchPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "first, prev and next pages"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or too long q, or invalid cursor or total"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "Search is not supported by the configured repository"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
+                    }
+                }
+            }
+        },
+        "/books/suggest": {
+            "get": {
+                "description": "Complete what is being typed in a search box with the titles and authors that have a word starting with prefix, then with the most similar ones, so typos still find them. Each suggestion counts the books with that title or author.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Suggest titles and authors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "hobit",
+                        "description": "Text typed so far",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Suggestions to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookSuggestions"
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or too long prefix"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "Suggestions are not supported by the configured repository"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             }
         },
         "/books/{id}": {
             "get": {
-                "description": "Fetch detailed information about a book using its unique ID.",
+                "description": "Fetch detailed information about a book using its unique ID. With the at parameter the book is returned as it was at that time, which requires the event sourced repository.",
                 "consumes": [
                     "application/json"
                 ],
@@ -121,6 +780,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "description": "RFC3339 timestamp to read the book at",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -128,16 +794,37 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/domain.Book"
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid ID format"
                     },
                     "404": {
-                        "description": "Book not found"
+                        "description": "Book not found",
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "hit, miss or stale, when the book cache was used"
+                            }
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "Book history is not supported by the configured repository"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             },
@@ -181,8 +868,17 @@ const docTemplate = `{
                     "404": {
                         "description": "Book to update not found"
                     },
+                    "409": {
+                        "description": "Another book with the provided Title and Author already exists, or the book was changed by another request"
+                    },
                     "500": {
                         "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             },
@@ -205,15 +901,24 @@ const docTemplate = `{
                     "200": {
                         "description": "Book Deleted Successfully"
                     },
+                    "409": {
+                        "description": "The book was changed by another request"
+                    },
                     "500": {
                         "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             }
         },
-        "/helloworld": {
-            "get": {
-                "description": "do ping",
+        "/books/{id}/merge": {
+            "post": {
+                "description": "Merge the book source_id into the book id. For title, author and year the request names the book whose value survives, the target book by default. The source book is removed, and a merge event is published.",
                 "consumes": [
                     "application/json"
                 ],
@@ -221,21 +926,73 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "example"
+                    "books"
+                ],
+                "summary": "Merge a duplicate book into a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the book to keep",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book to merge and the surviving field values",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BookMergeRequest"
+                        }
+                    }
                 ],
-                "summary": "ping example",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/domain.Book"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid merge request"
+                    },
+                    "404": {
+                        "description": "Book not found"
+                    },
+                    "409": {
+                        "description": "Another book with the merged Title and Author already exists, or a book was changed by another request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "Merging is not supported by the configured repository"
+                    },
+                    "503": {
+                        "description": "Request was cancelled"
+                    },
+                    "504": {
+                        "description": "Request timed out"
                     }
                 }
             }
         }
     },
     "definitions": {
+        "domain.AuthorCount": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "J.R.R. Tolkien"
+                },
+                "books": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
         "domain.Book": {
             "type": "object",
             "required": [
@@ -262,6 +1019,92 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.BookFacets": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "description": "Most books first, and at most the number asked for",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuthorCount"
+                    }
+                },
+                "decades": {
+                    "description": "Oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DecadeCount"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "years": {
+                    "description": "Oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.YearCount"
+                    }
+                }
+            }
+        },
+        "domain.BookMergeRequest": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "enum": [
+                        "target",
+                        "source"
+                    ],
+                    "example": "target"
+                },
+                "source_id": {
+                    "type": "integer",
+                    "example": 43
+                },
+                "title": {
+                    "type": "string",
+                    "enum": [
+                        "target",
+                        "source"
+                    ],
+                    "example": "source"
+                },
+                "year": {
+                    "type": "string",
+                    "enum": [
+                        "target",
+                        "source"
+                    ],
+                    "example": "target"
+                }
+            }
+        },
+        "domain.BookPage": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Book"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJxIjoiNjE0ZjQ3In0"
+                },
+                "prev_cursor": {
+                    "type": "string",
+                    "example": "eyJxIjoiNjE0ZjQ3IiwiYiI6dHJ1ZX0"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "domain.BookRequest": {
             "type": "object",
             "required": [
@@ -283,6 +1126,298 @@ const docTemplate = `{
                     "example": 1957
                 }
             }
+        },
+        "domain.BookSearchHit": {
+            "type": "object",
+            "required": [
+                "author",
+                "title",
+                "year"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "author_highlight": {
+                    "type": "string",
+                    "example": "J.R.R. Tolkien"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.0607927
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "title_highlight": {
+                    "type": "string",
+                    "example": "The \u003cmark\u003eHobbit\u003c/mark\u003e"
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 1957
+                }
+            }
+        },
+        "domain.BookSearchPage": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BookSearchHit"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJxIjoiMWtzZTV6YW1sIiwiciI6MC4wNiwiaSI6N30"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.BookSuggestion": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "field": {
+                    "type": "string",
+                    "enum": [
+                        "title",
+                        "author"
+                    ],
+                    "example": "title"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "The Hobbit"
+                }
+            }
+        },
+        "domain.BookSuggestions": {
+            "type": "object",
+            "properties": {
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BookSuggestion"
+                    }
+                }
+            }
+        },
+        "domain.CacheFlushResult": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CacheStats": {
+            "type": "object",
+            "properties": {
+                "book_keys": {
+                    "type": "integer"
+                },
+                "current_list_keys": {
+                    "description": "Pages cached under the current list version",
+                    "type": "integer"
+                },
+                "keys": {
+                    "type": "integer"
+                },
+                "list_keys": {
+                    "type": "integer"
+                },
+                "list_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CacheWarmResult": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "integer"
+                },
+                "pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CachedBook": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "cached": {
+                    "type": "boolean"
+                },
+                "fresh_until": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "not_found": {
+                    "description": "The book was cached as missing",
+                    "type": "boolean"
+                },
+                "stale": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "aggregate_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dead_lettered_at": {
+                    "type": "string"
+                },
+                "discarded_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "kafka: broker not available"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "outbox_id": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "replayed_at": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "book_events"
+                }
+            }
+        },
+        "domain.DecadeCount": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "decade": {
+                    "type": "integer",
+                    "example": 1930
+                }
+            }
+        },
+        "domain.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "score": {
+                    "description": "1 means title and author are the same once canonicalized",
+                    "type": "number",
+                    "example": 0.95
+                }
+            }
+        },
+        "domain.DuplicateResponse": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DuplicateCandidate"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Possible duplicate books found, retry with force=true to create the book anyway"
+                }
+            }
+        },
+        "domain.OutboxStats": {
+            "type": "object",
+            "properties": {
+                "dead_lettered": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "dead_letters_pending": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "lag_seconds": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "oldest_pending_at": {
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "published": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "domain.YearCount": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 1937
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
+
+// BookCommandHandler applies book commands read from Kafka, validating them exactly like the HTTP handlers
+type BookCommandHandler struct {
+	BookInteractor BookService
+}
+
+func NewBookCommandHandler(bookService BookService) *BookCommandHandler {
+	if bookService == nil {
+		return nil
+	}
+	return &BookCommandHandler{
+		BookInteractor: bookService,
+	}
+}
+
+// Handle decodes and applies a single command.
+// Errors wrapping domain.ErrInvalidBookCommand will fail on every retry.
+func (h *BookCommandHandler) Handle(ctx context.Context, payload []byte) error {
+	var cmd domain.BookCommand
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBookCommand, err.Error())
+	}
+
+	switch cmd.Type {
+	case domain.CreateBookCommand:
+		if err := validateCommandBook(cmd); err != nil {
+			return err
+		}
+		err := h.BookInteractor.CreateBook(ctx, cmd.Book, cmd.Force)
+		var duplicates *domain.DuplicateCandidatesError
+		if errors.As(err, &duplicates) {
+			return fmt.Errorf("%w: book with Title %s and Author %s looks like %d existing books, retry with force to create it anyway", domain.ErrInvalidBookCommand, cmd.Book.Title, cmd.Book.Author, len(duplicates.Candidates))
+		}
+		if err == gorm.ErrDuplicatedKey {
+			return fmt.Errorf("%w: book with Title %s and Author %s already exists", domain.ErrInvalidBookCommand, cmd.Book.Title, cmd.Book.Author)
+		}
+		return err
+	case domain.UpdateBookCommand:
+		if cmd.ID <= 0 {
+			return fmt.Errorf("%w: missing book ID", domain.ErrInvalidBookCommand)
+		}
+		if err := validateCommandBook(cmd); err != nil {
+			return err
+		}
+		err := h.BookInteractor.UpdateBookByID(ctx, cmd.ID, *cmd.Book)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: book for ID %d not found", domain.ErrInvalidBookCommand, cmd.ID)
+		}
+		if err == gorm.ErrDuplicatedKey {
+			return fmt.Errorf("%w: book with Title %s and Author %s already exists", domain.ErrInvalidBookCommand, cmd.Book.Title, cmd.Book.Author)
+		}
+		return err
+	case domain.DeleteBookCommand:
+		if cmd.ID <= 0 {
+			return fmt.Errorf("%w: missing book ID", domain.ErrInvalidBookCommand)
+		}
+		return h.BookInteractor.DeleteBookByID(ctx, cmd.ID)
+	default:
+		return fmt.Errorf("%w: unknown command type %q", domain.ErrInvalidBookCommand, cmd.Type)
+	}
+}
+
+func validateCommandBook(cmd domain.BookCommand) error {
+	if cmd.Book == nil {
+		return fmt.Errorf("%w: missing book", domain.ErrInvalidBookCommand)
+	}
+	if err := cmd.Book.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidBookCommand, err.Error())
+	}
+	return nil
+}
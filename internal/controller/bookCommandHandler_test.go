@@ -0,0 +1,58 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/controller"
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+func TestBookCommandHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		wantInvalid bool
+		wantBook    *domain.Book
+	}{
+		{name: "create", payload: `{"type":"create","book":{"title":"Emma","author":"Jane Austen","year":1815}}`, wantBook: &domain.Book{ID: 2, Title: "Emma", Author: "Jane Austen", Year: 1815}},
+		{name: "update", payload: `{"type":"update","id":1,"book":{"title":"Dune","author":"Frank Herbert","year":1966}}`, wantBook: &domain.Book{ID: 1, Title: "Dune", Author: "Frank Herbert", Year: 1966}},
+		{name: "delete", payload: `{"type":"delete","id":1}`},
+		{name: "delete missing book", payload: `{"type":"delete","id":404}`},
+		{name: "malformed JSON", payload: `{"type":`, wantInvalid: true},
+		{name: "unknown type", payload: `{"type":"archive","id":1}`, wantInvalid: true},
+		{name: "create without book", payload: `{"type":"create"}`, wantInvalid: true},
+		{name: "create invalid book", payload: `{"type":"create","book":{"title":"Emma","author":"Jane Austen","year":1200}}`, wantInvalid: true},
+		{name: "create duplicate", payload: `{"type":"create","book":{"title":"dune","author":"frank herbert","year":1965}}`, wantInvalid: true},
+		{name: "create likely duplicate", payload: `{"type":"create","book":{"title":"Dune","author":"Herbert, Frank","year":1966}}`, wantInvalid: true},
+		{name: "create likely duplicate forced", payload: `{"type":"create","book":{"title":"Dune","author":"Herbert, Frank","year":1966},"force":true}`, wantBook: &domain.Book{ID: 2, Title: "Dune", Author: "Herbert, Frank", Year: 1966}},
+		{name: "update without ID", payload: `{"type":"update","book":{"title":"Dune","author":"Frank Herbert","year":1966}}`, wantInvalid: true},
+		{name: "update missing book", payload: `{"type":"update","id":404,"book":{"title":"Dune","author":"Frank Herbert","year":1966}}`, wantInvalid: true},
+		{name: "delete without ID", payload: `{"type":"delete"}`, wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryBooksRepo(nil)
+			servicetest.CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+			handler := controller.NewBookCommandHandler(service.NewBookInteractor(repo, 0))
+
+			err := handler.Handle(context.Background(), []byte(tt.payload))
+			if invalid := errors.Is(err, domain.ErrInvalidBookCommand); invalid != tt.wantInvalid || (err != nil && !invalid) {
+				t.Fatalf("Handle returned %v, want invalid %t", err, tt.wantInvalid)
+			}
+			if tt.wantBook != nil {
+				book, err := repo.GetBookByID(context.Background(), tt.wantBook.ID)
+				if err != nil {
+					t.Fatalf("GetBookByID(%d): %v", tt.wantBook.ID, err)
+				}
+				if *book != *tt.wantBook {
+					t.Errorf("book is %+v, want %+v", *book, *tt.wantBook)
+				}
+			}
+		})
+	}
+}
@@ -1,15 +1,21 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Redarcher9/Books-Management-System/internal/domain"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// errOffsetRemoved answers requests still paging the book list by offset
+var errOffsetRemoved = errors.New("offset is no longer supported, pass the next_cursor of the previous page as cursor")
+
 type BookController struct {
 	BookInteractor BookService
 }
@@ -25,48 +31,330 @@ func NewBookController(bookService BookService) *BookController {
 
 // GetAllBooks godoc
 // @Summary Get all books with pagination
-// @Description Retrieve all books with pagination. If the provided offset or limit is less than 0, default values of limit = 10 and offset = 0 will be applied automatically.
+// @Description Retrieve a page of books, optionally filtered and sorted. Pages are linked by opaque cursors, returned in the body and in an RFC 8288 Link header. If the provided limit is less than 1, the default of 10 will be applied automatically. Titles and authors are matched and sorted ignoring case and spacing.
 // @Tags books
 // @Accept json
 // @Produce json
-// @Param offset query int false "Offset for pagination" default(0) min(0)
-// @Param limit query int false "Limit for pagination" default(10) min(1) max(100)
-// @Success 200 {array} []domain.Book
+// @Param limit query int false "Books per page" default(10) min(1) max(100)
+// @Param cursor query string false "next_cursor or prev_cursor of a page with the same filters and sort"
+// @Param total query bool false "Count every book matching the filters" default(false)
+// @Param author query string false "Only books by this author" example(J.R.R. Tolkien)
+// @Param title query string false "Only books whose title contains this text" example(hobbit)
+// @Param year_from query int false "Only books published in or after this year" example(1900)
+// @Param year_to query int false "Only books published in or before this year" example(1999)
+// @Param ids query string false "Only books with these comma separated IDs, at most 100" example(1,2,3)
+// @Param sort query string false "Comma separated fields to sort by (id, title, author, year), each descending when prefixed with -" default(id) example(-year,title)
+// @Success 200 {object} domain.BookPage
+// @Header 200 {string} Link "first, prev and next pages"
+// @Header 200 {string} X-Cache "hit, miss or stale, when the book cache was used"
+// @Failure 400 "Invalid filter, sort, cursor or total, or the removed offset parameter"
 // @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
 // @Router /books [get]
 func (bc *BookController) GetBooks(g *gin.Context) {
 	// Get Query parameters with default values
-	offset, err := strconv.Atoi(g.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	limit, err := strconv.Atoi(g.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
 	}
 
+	query, err := bookQuery(g)
+	if _, ok := g.GetQuery("offset"); ok && err == nil {
+		// Pages used to be read by offset, which now would silently return the first page
+		err = errOffsetRemoved
+	}
+	if err == nil {
+		err = query.Validate()
+	}
+	if err == nil && g.Query("cursor") != "" {
+		query.Cursor, err = domain.DecodeBookCursor(g.Query("cursor"), query)
+	}
+	if err == nil {
+		query.Total, err = boolQuery(g, "total")
+	}
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid query: %s", err.Error()),
+		})
+		return
+	}
+	query.Limit = limit
+
+	ctx, cacheStatus := domain.WithCacheStatusRecorder(g.Request.Context())
+	page, err := bc.BookInteractor.GetBooks(ctx, query)
+	setCacheHeader(g, cacheStatus)
+	if err != nil {
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	setLinkHeader(g, page.PrevCursor, page.NextCursor)
+	g.JSON(http.StatusOK, page)
+}
+
+// GetBookFacets godoc
+// @Summary Count books by author, decade and year
+// @Description Count the books matching the same filters as the book list by author, most books first, and by decade and year, oldest first, for filters in a sidebar. Authors are counted ignoring case and spacing. Author and year are the only categorical fields of a book, so there are no other facets.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param authors query int false "Authors to count" default(10) min(1) max(100)
+// @Param author query string false "Only books by this author" example(J.R.R. Tolkien)
+// @Param title query string false "Only books whose title contains this text" example(hobbit)
+// @Param year_from query int false "Only books published in or after this year" example(1900)
+// @Param year_to query int false "Only books published in or before this year" example(1999)
+// @Param ids query string false "Only books with these comma separated IDs, at most 100" example(1,2,3)
+// @Success 200 {object} domain.BookFacets
+// @Header 200 {string} X-Cache "hit, miss or stale, when the book cache was used"
+// @Failure 400 "Invalid filter or authors"
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /books/facets [get]
+func (bc *BookController) GetBookFacets(g *gin.Context) {
+	query, err := bookQuery(g)
+	authors, authorsErr := strconv.Atoi(g.DefaultQuery("authors", "10"))
+	if err == nil && (authorsErr != nil || authors < 1 || authors > domain.MaxFacetAuthors) {
+		err = fmt.Errorf("authors must be a number from 1 to %d", domain.MaxFacetAuthors)
+	}
+	if err == nil {
+		// Counts are the same however the books are sorted
+		query.Sort = nil
+		err = query.Validate()
+	}
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid query: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx, cacheStatus := domain.WithCacheStatusRecorder(g.Request.Context())
+	facets, err := bc.BookInteractor.GetBookFacets(ctx, query, authors)
+	setCacheHeader(g, cacheStatus)
+	if err != nil {
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, facets)
+}
+
+// SearchBooks godoc
+// @Summary Search books
+// @Description Full-text search of book titles and authors, most relevant books first. Every word of q must match, words in the title rank higher than words in the author, and the matching words are highlighted with <mark> elements in the HTML escaped title_highlight and author_highlight. Pages are linked by opaque cursors like the book list.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param q query string true "Words to search for" example(tolkien hobbit)
+// @Param limit query int false "Books per page" default(10) min(1) max(100)
+// @Param cursor query string false "next_cursor or prev_cursor of a page with the same q"
+// @Param total query bool false "Count every matching book" default(false)
+// @Success 200 {object} domain.BookSearchPage
+// @Header 200 {string} Link "first, prev and next pages"
+// @Header 200 {string} X-Cache "hit, miss or stale, when the book cache was used"
+// @Failure 400 "Missing or too long q, or invalid cursor or total"
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "Search is not supported by the configured repository"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /books/search [get]
+func (bc *BookController) SearchBooks(g *gin.Context) {
 	limit, err := strconv.Atoi(g.DefaultQuery("limit", "10"))
 	if err != nil || limit < 1 {
 		limit = 10
 	}
 
-	books, err := bc.BookInteractor.GetBooks(g, offset, limit)
+	query := domain.BookSearchQuery{Text: g.Query("q")}
+	err = query.Validate()
+	if err == nil && g.Query("cursor") != "" {
+		query.Cursor, err = domain.DecodeBookSearchCursor(g.Query("cursor"), query)
+	}
+	if err == nil {
+		query.Total, err = boolQuery(g, "total")
+	}
 	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid query: %s", err.Error()),
+		})
+		return
+	}
+	query.Limit = limit
+
+	ctx, cacheStatus := domain.WithCacheStatusRecorder(g.Request.Context())
+	page, err := bc.BookInteractor.SearchBooks(ctx, query)
+	setCacheHeader(g, cacheStatus)
+	if err != nil {
+		if err == domain.ErrNotSupported {
+			g.JSON(http.StatusNotImplemented, domain.ErrorResponse{
+				Message: "Search is not supported by the configured repository",
+			})
+			return
+		}
+		if requestAborted(g, err) {
+			return
+		}
 		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
 			Message: "Internal Server Error",
 		})
 		return
 	}
-	g.JSON(http.StatusOK, books)
+	setLinkHeader(g, page.PrevCursor, page.NextCursor)
+	g.JSON(http.StatusOK, page)
+}
+
+// SuggestBooks godoc
+// @Summary Suggest titles and authors
+// @Description Complete what is being typed in a search box with the titles and authors that have a word starting with prefix, then with the most similar ones, so typos still find them. Each suggestion counts the books with that title or author.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param prefix query string true "Text typed so far" example(hobit)
+// @Param limit query int false "Suggestions to return" default(5) min(1) max(20)
+// @Success 200 {object} domain.BookSuggestions
+// @Header 200 {string} X-Cache "hit, miss or stale, when the book cache was used"
+// @Failure 400 "Missing or too long prefix"
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "Suggestions are not supported by the configured repository"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /books/suggest [get]
+func (bc *BookController) SuggestBooks(g *gin.Context) {
+	limit, err := strconv.Atoi(g.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+
+	query := domain.BookSuggestQuery{Prefix: g.Query("prefix"), Limit: limit}
+	if err := query.Validate(); err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid query: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx, cacheStatus := domain.WithCacheStatusRecorder(g.Request.Context())
+	suggestions, err := bc.BookInteractor.SuggestBooks(ctx, query)
+	setCacheHeader(g, cacheStatus)
+	if err != nil {
+		if err == domain.ErrNotSupported {
+			g.JSON(http.StatusNotImplemented, domain.ErrorResponse{
+				Message: "Suggestions are not supported by the configured repository",
+			})
+			return
+		}
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, suggestions)
+}
+
+// setLinkHeader links the first page and the pages at the prev and next cursors as in RFC 8288,
+// keeping every other parameter of the request
+func setLinkHeader(g *gin.Context, prevCursor, nextCursor string) {
+	links := make([]string, 0, 3)
+	link := func(rel, cursor string) {
+		params := g.Request.URL.Query()
+		params.Del("cursor")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		target := g.Request.URL.Path
+		if len(params) > 0 {
+			target += "?" + params.Encode()
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target, rel))
+	}
+
+	link("first", "")
+	if prevCursor != "" {
+		link("prev", prevCursor)
+	}
+	if nextCursor != "" {
+		link("next", nextCursor)
+	}
+	g.Header("Link", strings.Join(links, ", "))
+}
+
+// bookQuery reads the filters and sort of GET /books
+func bookQuery(g *gin.Context) (domain.BookQuery, error) {
+	query := domain.BookQuery{
+		Author: g.Query("author"),
+		Title:  g.Query("title"),
+		Sort:   domain.ParseBookSort(g.Query("sort")),
+	}
+
+	years := []struct {
+		name  string
+		value *int
+	}{{"year_from", &query.YearFrom}, {"year_to", &query.YearTo}}
+	for _, year := range years {
+		param := g.Query(year.name)
+		if param == "" {
+			continue
+		}
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			return query, fmt.Errorf("%s must be a year", year.name)
+		}
+		*year.value = value
+	}
+
+	if param := g.Query("ids"); param != "" {
+		for _, IDParam := range strings.Split(param, ",") {
+			ID, err := strconv.Atoi(strings.TrimSpace(IDParam))
+			if err != nil {
+				return query, errors.New("ids must be comma separated book IDs")
+			}
+			query.IDs = append(query.IDs, ID)
+		}
+	}
+	return query, nil
+}
+
+// boolQuery reads the optional boolean query parameter name, false when it is missing
+func boolQuery(g *gin.Context, name string) (bool, error) {
+	param := g.Query(name)
+	if param == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return value, nil
 }
 
 // GetBookByID godoc
 // @Summary Get a book by ID
-// @Description Fetch detailed information about a book using its unique ID.
+// @Description Fetch detailed information about a book using its unique ID. With the at parameter the book is returned as it was at that time, which requires the event sourced repository.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
+// @Param at query string false "RFC3339 timestamp to read the book at" example(2025-01-01T00:00:00Z)
 // @Success 200 {object} domain.Book
 // @Failure 400 "Invalid ID format"
 // @Failure 404 "Book not found"
 // @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Failure 501  "Book history is not supported by the configured repository"
+// @Header 200,404 {string} X-Cache "hit, miss or stale, when the book cache was used"
 // @Router /books/{id} [get]
 func (bc *BookController) GetBookByID(g *gin.Context) {
 	// Get the 'ID' parameter
@@ -81,14 +369,37 @@ func (bc *BookController) GetBookByID(g *gin.Context) {
 		return
 	}
 
-	book, err := bc.BookInteractor.GetBookByID(g, id)
+	var book *domain.Book
+	if atParam := g.Query("at"); atParam != "" {
+		at, parseErr := time.Parse(time.RFC3339, atParam)
+		if parseErr != nil {
+			g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+				Message: "Invalid at format, expected RFC3339",
+			})
+			return
+		}
+		book, err = bc.BookInteractor.GetBookAt(g.Request.Context(), id, at)
+	} else {
+		ctx, cacheStatus := domain.WithCacheStatusRecorder(g.Request.Context())
+		book, err = bc.BookInteractor.GetBookByID(ctx, id)
+		setCacheHeader(g, cacheStatus)
+	}
 	if err != nil {
+		if err == domain.ErrNotSupported {
+			g.JSON(http.StatusNotImplemented, domain.ErrorResponse{
+				Message: "Book history is not supported by the configured repository",
+			})
+			return
+		}
 		if err == gorm.ErrRecordNotFound {
 			g.JSON(http.StatusNotFound, domain.ErrorResponse{
 				Message: fmt.Sprintf("Book for ID %d not found", id),
 			})
 			return
 		}
+		if requestAborted(g, err) {
+			return
+		}
 		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
 			Message: "Internal Server Error",
 		})
@@ -103,7 +414,10 @@ func (bc *BookController) GetBookByID(g *gin.Context) {
 // @Tags books
 // @Param id path int true "Book ID"
 // @Success 200 "Book Deleted Successfully"
+// @Failure 409 "The book was changed by another request"
 // @Failure 500 "Internal Server Error"
+// @Failure 503 "Request was cancelled"
+// @Failure 504 "Request timed out"
 // @Router /books/{id} [delete]
 func (bc *BookController) DeleteBookByID(g *gin.Context) {
 	// Get the 'ID' parameter
@@ -118,8 +432,11 @@ func (bc *BookController) DeleteBookByID(g *gin.Context) {
 		return
 	}
 
-	err = bc.BookInteractor.DeleteBookByID(g, id)
+	err = bc.BookInteractor.DeleteBookByID(g.Request.Context(), id)
 	if err != nil {
+		if concurrentModification(g, err) || requestAborted(g, err) {
+			return
+		}
 		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
 			Message: "Internal Server Error",
 		})
@@ -139,7 +456,10 @@ func (bc *BookController) DeleteBookByID(g *gin.Context) {
 // @Success 200 "Book updated successfully"
 // @Failure 400 "Validation Error"
 // @Failure 404  "Book to update not found"
+// @Failure 409  "Another book with the provided Title and Author already exists, or the book was changed by another request"
 // @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
 // @Router /books/{id} [put]
 func (bc *BookController) UpdateBookByID(g *gin.Context) {
 	// Get the 'ID' parameter
@@ -171,7 +491,7 @@ func (bc *BookController) UpdateBookByID(g *gin.Context) {
 	}
 
 	// call Service for updating
-	err = bc.BookInteractor.UpdateBookByID(g, id, req)
+	err = bc.BookInteractor.UpdateBookByID(g.Request.Context(), id, req)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			g.JSON(http.StatusNotFound, domain.ErrorResponse{
@@ -179,6 +499,15 @@ func (bc *BookController) UpdateBookByID(g *gin.Context) {
 			})
 			return
 		}
+		if err == gorm.ErrDuplicatedKey {
+			g.JSON(http.StatusConflict, domain.ErrorResponse{
+				Message: fmt.Sprintf("Another book with Title %s and Author %s already exists", req.Title, req.Author),
+			})
+			return
+		}
+		if concurrentModification(g, err) || requestAborted(g, err) {
+			return
+		}
 		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
 			Message: "Internal Server Error",
 		})
@@ -189,15 +518,19 @@ func (bc *BookController) UpdateBookByID(g *gin.Context) {
 
 // CreateBook godoc
 // @Summary Create a new book
-// @Description Create a new book with title, author, and year
+// @Description Create a new book with title, author, and year.
+// @Description Unless force is true, the book is rejected when it looks like an existing book, for example "Hobbit, The" by "Tolkien, J. R. R." for "The Hobbit" by "J.R.R. Tolkien", and the likely duplicates are listed.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param book body domain.BookRequest true "Book data to create"
+// @Param force query bool false "Create the book even if it looks like an existing book" default(false)
 // @Success 201 "Book Created Successfully"
-// @Failure 400 "Validation Error"
-// @Failure 409  "Book with provided Title and Author already exists"
+// @Failure 400 "Validation Error, or force is neither true nor false"
+// @Failure 409  {object} domain.DuplicateResponse "Book with provided Title and Author already exists, or likely duplicates were found"
 // @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
 // @Router /books [post]
 func (bc *BookController) CreateBook(g *gin.Context) {
 	var req domain.Book
@@ -216,14 +549,33 @@ func (bc *BookController) CreateBook(g *gin.Context) {
 		return
 	}
 
-	err := bc.BookInteractor.CreateBook(g, &req)
+	force, err := boolQuery(g, "force")
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid query: %s", err.Error()),
+		})
+		return
+	}
+
+	err = bc.BookInteractor.CreateBook(g.Request.Context(), &req, force)
 	if err != nil {
+		var duplicates *domain.DuplicateCandidatesError
+		if errors.As(err, &duplicates) {
+			g.JSON(http.StatusConflict, domain.DuplicateResponse{
+				Message:    "Possible duplicate books found, retry with force=true to create the book anyway",
+				Candidates: duplicates.Candidates,
+			})
+			return
+		}
 		if err == gorm.ErrDuplicatedKey {
 			g.JSON(http.StatusConflict, domain.ErrorResponse{
 				Message: fmt.Sprintf("Book with Title %s and Author %s already exists", req.Title, req.Author),
 			})
 			return
 		}
+		if concurrentModification(g, err) || requestAborted(g, err) {
+			return
+		}
 		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
 			Message: err.Error(),
 		})
@@ -232,3 +584,85 @@ func (bc *BookController) CreateBook(g *gin.Context) {
 
 	g.JSON(http.StatusCreated, req)
 }
+
+// setCacheHeader reports in X-Cache whether the response was served from the book cache
+func setCacheHeader(g *gin.Context, recorder *domain.CacheStatusRecorder) {
+	if status := recorder.Status(); status != "" {
+		g.Header("X-Cache", string(status))
+	}
+}
+
+// MergeBooks godoc
+// @Summary Merge a duplicate book into a book
+// @Description Merge the book source_id into the book id. For title, author and year the request names the book whose value survives, the target book by default. The source book is removed, and a merge event is published.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "ID of the book to keep"
+// @Param merge body domain.BookMergeRequest true "Book to merge and the surviving field values"
+// @Success 200 {object} domain.Book
+// @Failure 400 "Invalid merge request"
+// @Failure 404 "Book not found"
+// @Failure 409 "Another book with the merged Title and Author already exists, or a book was changed by another request"
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "Merging is not supported by the configured repository"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /books/{id}/merge [post]
+func (bc *BookController) MergeBooks(g *gin.Context) {
+	// Get the 'ID' parameter
+	IDParam := g.Param("id")
+
+	// Convert 'ID' to Int
+	id, err := strconv.Atoi(IDParam)
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: "Invalid ID format",
+		})
+		return
+	}
+
+	var req domain.BookMergeRequest
+	if err := g.ShouldBindJSON(&req); err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: fmt.Sprintf("Invalid data: %s", err.Error()),
+		})
+		return
+	}
+
+	book, err := bc.BookInteractor.MergeBooks(g.Request.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMerge) {
+			g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+				Message: err.Error(),
+			})
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			g.JSON(http.StatusNotFound, domain.ErrorResponse{
+				Message: fmt.Sprintf("Book for ID %d or %d not found", id, req.SourceID),
+			})
+			return
+		}
+		if err == gorm.ErrDuplicatedKey {
+			g.JSON(http.StatusConflict, domain.ErrorResponse{
+				Message: "Another book with the merged Title and Author already exists",
+			})
+			return
+		}
+		if err == domain.ErrNotSupported {
+			g.JSON(http.StatusNotImplemented, domain.ErrorResponse{
+				Message: "Merging is not supported by the configured repository",
+			})
+			return
+		}
+		if concurrentModification(g, err) || requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, book)
+}
@@ -0,0 +1,232 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache/cachetest"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/routes"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newRouter serves the API over repo, relaying and dead-lettering the events in outbox
+func newRouter(t *testing.T, repo service.BookRepo, outbox *repository.MemoryOutbox) *gin.Engine {
+	t.Helper()
+	router := gin.New()
+	routes.SetupRoutes(router,
+		service.NewBookInteractor(repo, 0),
+		service.NewOutboxRelay(outbox, servicetest.NewEventBus(), 0, 0, domain.RetryPolicy{}),
+		service.NewDeadLetterInteractor(outbox),
+		service.NewCacheInteractor(repo),
+	)
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestBookRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/v1/books?limit=2", wantStatus: http.StatusOK, wantBody: `"Dune"`},
+		{name: "list filtered and sorted", method: http.MethodGet, path: "/api/v1/books?title=HOBBIT&sort=-year", wantStatus: http.StatusOK, wantBody: `[{"id":3,`},
+		{name: "list by IDs", method: http.MethodGet, path: "/api/v1/books?ids=2,%202", wantStatus: http.StatusOK, wantBody: `[{"id":2,`},
+		{name: "list empty page", method: http.MethodGet, path: "/api/v1/books?title=silmarillion", wantStatus: http.StatusOK, wantBody: `{"books":[]}`},
+		{name: "list with total", method: http.MethodGet, path: "/api/v1/books?limit=1&total=true", wantStatus: http.StatusOK, wantBody: `"total":3`},
+		{name: "list malformed cursor", method: http.MethodGet, path: "/api/v1/books?cursor=not-a-cursor", wantStatus: http.StatusBadRequest},
+		{name: "list unknown sort field", method: http.MethodGet, path: "/api/v1/books?sort=isbn", wantStatus: http.StatusBadRequest},
+		{name: "list invalid year", method: http.MethodGet, path: "/api/v1/books?year_from=recent", wantStatus: http.StatusBadRequest},
+		{name: "list inverted year range", method: http.MethodGet, path: "/api/v1/books?year_from=2000&year_to=1900", wantStatus: http.StatusBadRequest},
+		{name: "list invalid IDs", method: http.MethodGet, path: "/api/v1/books?ids=1,two", wantStatus: http.StatusBadRequest},
+		{name: "list invalid total", method: http.MethodGet, path: "/api/v1/books?total=yes", wantStatus: http.StatusBadRequest, wantBody: `total must be true or false`},
+		{name: "list by offset", method: http.MethodGet, path: "/api/v1/books?offset=10", wantStatus: http.StatusBadRequest, wantBody: `as cursor`},
+		{name: "facets", method: http.MethodGet, path: "/api/v1/books/facets", wantStatus: http.StatusOK, wantBody: `{"total":3,"authors":[{"author":"Frank Herbert","books":1},`},
+		{name: "facets filtered", method: http.MethodGet, path: "/api/v1/books/facets?title=hobbit&authors=1", wantStatus: http.StatusOK, wantBody: `"authors":[{"author":"J.R.R. Tolkien","books":1}],"decades":[{"decade":1930,"books":1},{"decade":1950,"books":1}]`},
+		{name: "facets without books", method: http.MethodGet, path: "/api/v1/books/facets?title=silmarillion", wantStatus: http.StatusOK, wantBody: `{"total":0,"authors":[],"decades":[],"years":[]}`},
+		{name: "facets ignore the sort", method: http.MethodGet, path: "/api/v1/books/facets?sort=isbn", wantStatus: http.StatusOK},
+		{name: "facets inverted year range", method: http.MethodGet, path: "/api/v1/books/facets?year_from=2000&year_to=1900", wantStatus: http.StatusBadRequest},
+		{name: "facets authors not a number", method: http.MethodGet, path: "/api/v1/books/facets?authors=abc", wantStatus: http.StatusBadRequest, wantBody: `authors must be a number from 1 to 100`},
+		{name: "facets no authors", method: http.MethodGet, path: "/api/v1/books/facets?authors=0", wantStatus: http.StatusBadRequest},
+		{name: "facets too many authors", method: http.MethodGet, path: "/api/v1/books/facets?authors=101", wantStatus: http.StatusBadRequest},
+		{name: "facets most authors", method: http.MethodGet, path: "/api/v1/books/facets?authors=100", wantStatus: http.StatusOK, wantBody: `"total":3`},
+		{name: "search", method: http.MethodGet, path: "/api/v1/books/search?q=DUNE", wantStatus: http.StatusOK, wantBody: `"title_highlight":"\u003cmark\u003eDune\u003c/mark\u003e"`},
+		{name: "search with total", method: http.MethodGet, path: "/api/v1/books/search?q=hobbit&limit=1&total=true", wantStatus: http.StatusOK, wantBody: `"total":2`},
+		{name: "search without results", method: http.MethodGet, path: "/api/v1/books/search?q=silmarillion", wantStatus: http.StatusOK, wantBody: `{"books":[]}`},
+		{name: "search without q", method: http.MethodGet, path: "/api/v1/books/search?q=%20", wantStatus: http.StatusBadRequest},
+		{name: "search invalid total", method: http.MethodGet, path: "/api/v1/books/search?q=dune&total=1x", wantStatus: http.StatusBadRequest, wantBody: `total must be true or false`},
+		{name: "search cursor of a list", method: http.MethodGet, path: "/api/v1/books/search?q=dune&cursor=eyJxIjoiMCIsImkiOjF9", wantStatus: http.StatusBadRequest},
+		{name: "suggest", method: http.MethodGet, path: "/api/v1/books/suggest?prefix=hobit", wantStatus: http.StatusOK, wantBody: `{"suggestions":[{"field":"title","text":"The Hobbit","books":2}]}`},
+		{name: "suggest without matches", method: http.MethodGet, path: "/api/v1/books/suggest?prefix=austen", wantStatus: http.StatusOK, wantBody: `{"suggestions":[]}`},
+		{name: "suggest without prefix", method: http.MethodGet, path: "/api/v1/books/suggest", wantStatus: http.StatusBadRequest},
+		{name: "get", method: http.MethodGet, path: "/api/v1/books/1", wantStatus: http.StatusOK, wantBody: `"The Hobbit"`},
+		{name: "get missing", method: http.MethodGet, path: "/api/v1/books/404", wantStatus: http.StatusNotFound},
+		{name: "get invalid ID", method: http.MethodGet, path: "/api/v1/books/one", wantStatus: http.StatusBadRequest},
+		{name: "get at a time", method: http.MethodGet, path: "/api/v1/books/1?at=2025-01-01T00:00:00Z", wantStatus: http.StatusNotImplemented},
+		{name: "create", method: http.MethodPost, path: "/api/v1/books", body: `{"title":"Emma","author":"Jane Austen","year":1815}`, wantStatus: http.StatusCreated, wantBody: `"id":4`},
+		{name: "create invalid year", method: http.MethodPost, path: "/api/v1/books", body: `{"title":"Emma","author":"Jane Austen","year":1200}`, wantStatus: http.StatusBadRequest},
+		{name: "create invalid JSON", method: http.MethodPost, path: "/api/v1/books", body: `{"title":`, wantStatus: http.StatusBadRequest},
+		{name: "create likely duplicate", method: http.MethodPost, path: "/api/v1/books", body: `{"title":"Hobbit, The","author":"Tolkien, J. R. R.","year":1937}`, wantStatus: http.StatusConflict, wantBody: `"candidates"`},
+		{name: "create forced likely duplicate", method: http.MethodPost, path: "/api/v1/books?force=true", body: `{"title":"Hobbit, The","author":"Tolkien, J. R. R.","year":1937}`, wantStatus: http.StatusCreated},
+		{name: "create with invalid force", method: http.MethodPost, path: "/api/v1/books?force=yes", body: `{"title":"Hobbit, The","author":"Tolkien, J. R. R.","year":1937}`, wantStatus: http.StatusBadRequest, wantBody: "force must be true or false"},
+		{name: "create with force false", method: http.MethodPost, path: "/api/v1/books?force=false", body: `{"title":"Hobbit, The","author":"Tolkien, J. R. R.","year":1937}`, wantStatus: http.StatusConflict, wantBody: `"candidates"`},
+		{name: "create forced duplicate", method: http.MethodPost, path: "/api/v1/books?force=true", body: `{"title":"the hobbit","author":"j.r.r. tolkien","year":1937}`, wantStatus: http.StatusConflict, wantBody: "already exists"},
+		{name: "update", method: http.MethodPut, path: "/api/v1/books/2", body: `{"title":"Dune","author":"Frank Herbert","year":1966}`, wantStatus: http.StatusOK},
+		{name: "update missing", method: http.MethodPut, path: "/api/v1/books/404", body: `{"title":"Dune","author":"Frank Herbert","year":1966}`, wantStatus: http.StatusNotFound},
+		{name: "update to a duplicate", method: http.MethodPut, path: "/api/v1/books/2", body: `{"title":"The Hobbit","author":"J.R.R. Tolkien","year":1937}`, wantStatus: http.StatusConflict},
+		{name: "delete", method: http.MethodDelete, path: "/api/v1/books/2", wantStatus: http.StatusOK},
+		{name: "merge", method: http.MethodPost, path: "/api/v1/books/1/merge", body: `{"source_id":3,"year":"source"}`, wantStatus: http.StatusOK, wantBody: `"year":1951`},
+		{name: "merge into itself", method: http.MethodPost, path: "/api/v1/books/1/merge", body: `{"source_id":1}`, wantStatus: http.StatusBadRequest},
+		{name: "merge missing source", method: http.MethodPost, path: "/api/v1/books/1/merge", body: `{"source_id":404}`, wantStatus: http.StatusNotFound},
+		{name: "cache stats without a cache", method: http.MethodGet, path: "/api/v1/admin/cache", wantStatus: http.StatusNotImplemented},
+		{name: "outbox stats", method: http.MethodGet, path: "/api/v1/admin/outbox", wantStatus: http.StatusOK, wantBody: `"pending":3`},
+		{name: "replay missing dead letter", method: http.MethodPost, path: "/api/v1/admin/dead-letters/404/replay", wantStatus: http.StatusNotFound},
+		{name: "discard missing dead letter", method: http.MethodPost, path: "/api/v1/admin/dead-letters/404/discard", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := repository.NewMemoryOutbox()
+			repo := repository.NewMemoryBooksRepo(outbox)
+			servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+			servicetest.CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+			servicetest.CreateBook(t, repo, "The Hobbit", "Tolkien", 1951)
+
+			response := serve(newRouter(t, repo, outbox), tt.method, tt.path, tt.body)
+			if response.Code != tt.wantStatus {
+				t.Fatalf("%s %s returned %d, want %d: %s", tt.method, tt.path, response.Code, tt.wantStatus, response.Body)
+			}
+			if !strings.Contains(response.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s returned %s, want it to contain %s", tt.method, tt.path, response.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestBookRoutesLinks(t *testing.T) {
+	repo := repository.NewMemoryBooksRepo(nil)
+	for i, title := range []string{"Beloved", "Dune", "Emma"} {
+		servicetest.CreateBook(t, repo, title, "Author", 1900+i)
+	}
+	router := newRouter(t, repo, repository.NewMemoryOutbox())
+
+	// Follow the next links to the end, then the prev links back to the start
+	path := "/api/v1/books?limit=1&sort=-year"
+	var titles []string
+	for _, rel := range []string{"next", "next", "prev", "prev"} {
+		response := serve(router, http.MethodGet, path, "")
+		if response.Code != http.StatusOK {
+			t.Fatalf("GET %s returned %d: %s", path, response.Code, response.Body)
+		}
+		var page domain.BookPage
+		if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		titles = append(titles, page.Books[0].Title)
+
+		links := parseLinks(response.Header().Get("Link"))
+		if links["first"] != "/api/v1/books?limit=1&sort=-year" {
+			t.Errorf("first link is %q", links["first"])
+		}
+		if path = links[rel]; path == "" {
+			t.Fatalf("Link header %q has no %s link", response.Header().Get("Link"), rel)
+		}
+	}
+	response := serve(router, http.MethodGet, path, "")
+	var page domain.BookPage
+	if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	titles = append(titles, page.Books[0].Title)
+	if want := []string{"Emma", "Dune", "Beloved", "Dune", "Emma"}; !slices.Equal(titles, want) {
+		t.Errorf("pages are %v, want %v", titles, want)
+	}
+	if links := parseLinks(response.Header().Get("Link")); links["prev"] != "" {
+		t.Errorf("first page links to a previous page %q", links["prev"])
+	}
+
+	// The cursor does not fit another sort
+	cursor := page.NextCursor
+	if response := serve(router, http.MethodGet, "/api/v1/books?limit=1&sort=title&cursor="+cursor, ""); response.Code != http.StatusBadRequest {
+		t.Errorf("cursor with another sort returned %d, want %d", response.Code, http.StatusBadRequest)
+	}
+}
+
+// parseLinks returns the targets of the RFC 8288 Link header by relation type
+func parseLinks(header string) map[string]string {
+	links := make(map[string]string)
+	for _, link := range strings.Split(header, ", ") {
+		target, rel, ok := strings.Cut(link, `>; rel="`)
+		if ok {
+			links[strings.TrimSuffix(rel, `"`)] = strings.TrimPrefix(target, "<")
+		}
+	}
+	return links
+}
+
+func TestBookRoutesCache(t *testing.T) {
+	gormDB, err := repository.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	bookCache := cachetest.New()
+	repo := repository.NewBooksRepo(gormDB, bookCache, repository.CacheConfig{})
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	router := newRouter(t, repo, repository.NewMemoryOutbox())
+
+	for _, want := range []domain.CacheStatus{domain.CacheMiss, domain.CacheHit} {
+		response := serve(router, http.MethodGet, "/api/v1/books/1", "")
+		if response.Code != http.StatusOK {
+			t.Fatalf("GET /books/1 returned %d: %s", response.Code, response.Body)
+		}
+		if got := response.Header().Get("X-Cache"); got != string(want) {
+			t.Errorf("X-Cache = %q, want %q", got, want)
+		}
+		var got domain.Book
+		if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if got != book {
+			t.Errorf("GET /books/1 returned %+v, want %+v", got, book)
+		}
+	}
+
+	if response := serve(router, http.MethodGet, "/api/v1/admin/cache", ""); response.Code != http.StatusOK {
+		t.Errorf("GET /admin/cache returned %d, want %d: %s", response.Code, http.StatusOK, response.Body)
+	}
+	bookCache.FailWith(errors.New("redis: connection refused"))
+	if response := serve(router, http.MethodGet, "/api/v1/admin/cache", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /admin/cache returned %d while Redis is down, want %d: %s", response.Code, http.StatusServiceUnavailable, response.Body)
+	}
+	// Reads fall back to the database while Redis is down
+	if response := serve(router, http.MethodGet, "/api/v1/books/1", ""); response.Code != http.StatusOK {
+		t.Errorf("GET /books/1 returned %d while Redis is down, want %d: %s", response.Code, http.StatusOK, response.Body)
+	}
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type CacheController struct {
+	CacheInteractor CacheService
+}
+
+func NewCacheController(cacheService CacheService) *CacheController {
+	if cacheService == nil {
+		return nil
+	}
+	return &CacheController{
+		CacheInteractor: cacheService,
+	}
+}
+
+// GetCacheStats godoc
+// @Summary Inspect the book cache
+// @Description Count the keys of the book cache keyspace and report the current book list version.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.CacheStats
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "The configured repository has no cache"
+// @Failure 503  "Book cache is unavailable"
+// @Failure 504  "Request timed out"
+// @Router /admin/cache [get]
+func (cc *CacheController) GetCacheStats(g *gin.Context) {
+	stats, err := cc.CacheInteractor.GetCacheStats(g.Request.Context())
+	if err != nil {
+		cacheError(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, stats)
+}
+
+// GetCachedBook godoc
+// @Summary Inspect the cache entry of a book
+// @Description Show whether a book is cached, cached as missing, or stale.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} domain.CachedBook
+// @Failure 400 "Invalid ID format"
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "The configured repository has no cache"
+// @Failure 503  "Book cache is unavailable"
+// @Failure 504  "Request timed out"
+// @Router /admin/cache/books/{id} [get]
+func (cc *CacheController) GetCachedBook(g *gin.Context) {
+	id, err := strconv.Atoi(g.Param("id"))
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: "Invalid ID format",
+		})
+		return
+	}
+
+	cachedBook, err := cc.CacheInteractor.GetCachedBook(g.Request.Context(), id)
+	if err != nil {
+		cacheError(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, cachedBook)
+}
+
+// WarmCache godoc
+// @Summary Warm the book cache
+// @Description Load up to count books from the database, caching them one by one and as book list pages of page_size books.
+// @Tags admin
+// @Produce json
+// @Param count query int false "Number of books to load" default(100) min(1)
+// @Param page_size query int false "Books per cached list page" default(10) min(1) max(100)
+// @Success 200 {object} domain.CacheWarmResult
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "The configured repository has no cache"
+// @Failure 503  "Book cache is unavailable"
+// @Failure 504  "Request timed out"
+// @Router /admin/cache/warm [post]
+func (cc *CacheController) WarmCache(g *gin.Context) {
+	count, err := strconv.Atoi(g.DefaultQuery("count", "100"))
+	if err != nil || count < 1 {
+		count = 100
+	}
+
+	pageSize, err := strconv.Atoi(g.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	result, err := cc.CacheInteractor.WarmCache(g.Request.Context(), count, pageSize)
+	if err != nil {
+		cacheError(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, result)
+}
+
+// FlushCache godoc
+// @Summary Flush the book cache
+// @Description Delete every key of the book cache keyspace and clear the in-process cache of every instance.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.CacheFlushResult
+// @Failure 500  "Internal Server Error"
+// @Failure 501  "The configured repository has no cache"
+// @Failure 503  "Book cache is unavailable"
+// @Failure 504  "Request timed out"
+// @Router /admin/cache [delete]
+func (cc *CacheController) FlushCache(g *gin.Context) {
+	result, err := cc.CacheInteractor.FlushCache(g.Request.Context())
+	if err != nil {
+		cacheError(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, result)
+}
+
+func cacheError(g *gin.Context, err error) {
+	if requestAborted(g, err) {
+		return
+	}
+	switch err {
+	case domain.ErrNotSupported:
+		g.JSON(http.StatusNotImplemented, domain.ErrorResponse{
+			Message: "The configured repository has no cache",
+		})
+	case domain.ErrCacheUnavailable:
+		g.JSON(http.StatusServiceUnavailable, domain.ErrorResponse{
+			Message: "Book cache is unavailable",
+		})
+	default:
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeadLetterController struct {
+	DeadLetterInteractor DeadLetterService
+}
+
+func NewDeadLetterController(deadLetterService DeadLetterService) *DeadLetterController {
+	if deadLetterService == nil {
+		return nil
+	}
+	return &DeadLetterController{
+		DeadLetterInteractor: deadLetterService,
+	}
+}
+
+// GetDeadLetters godoc
+// @Summary List dead-lettered events
+// @Description List book events that ran out of publish attempts, most recent first. If the provided offset or limit is less than 0, default values of limit = 10 and offset = 0 will be applied automatically.
+// @Tags admin
+// @Produce json
+// @Param offset query int false "Offset for pagination" default(0) min(0)
+// @Param limit query int false "Limit for pagination" default(10) min(1) max(100)
+// @Success 200 {array} domain.DeadLetter
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /admin/dead-letters [get]
+func (dc *DeadLetterController) GetDeadLetters(g *gin.Context) {
+	// Get Query parameters with default values
+	offset, err := strconv.Atoi(g.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err := strconv.Atoi(g.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	deadLetters, err := dc.DeadLetterInteractor.GetDeadLetters(g.Request.Context(), offset, limit)
+	if err != nil {
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, deadLetters)
+}
+
+// GetDeadLetterByID godoc
+// @Summary Get a dead-lettered event by ID
+// @Description Inspect a dead-lettered event, including its payload and the last publish error.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Dead letter ID"
+// @Success 200 {object} domain.DeadLetter
+// @Failure 400 "Invalid ID format"
+// @Failure 404 "Dead letter not found"
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /admin/dead-letters/{id} [get]
+func (dc *DeadLetterController) GetDeadLetterByID(g *gin.Context) {
+	id, err := strconv.ParseInt(g.Param("id"), 10, 64)
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: "Invalid ID format",
+		})
+		return
+	}
+
+	deadLetter, err := dc.DeadLetterInteractor.GetDeadLetterByID(g.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			g.JSON(http.StatusNotFound, domain.ErrorResponse{
+				Message: fmt.Sprintf("Dead letter for ID %d not found", id),
+			})
+			return
+		}
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, deadLetter)
+}
+
+// ReplayDeadLetter godoc
+// @Summary Replay a dead-lettered event
+// @Description Put a dead-lettered event back into the outbox so it is published again, ahead of the later events for the same book that were held back behind it.
+// @Tags admin
+// @Param id path int true "Dead letter ID"
+// @Success 202 "Dead letter queued for replay"
+// @Failure 400 "Invalid ID format"
+// @Failure 404 "Dead letter not found"
+// @Failure 409 "Dead letter was already replayed or discarded"
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /admin/dead-letters/{id}/replay [post]
+func (dc *DeadLetterController) ReplayDeadLetter(g *gin.Context) {
+	dc.resolveDeadLetter(g, dc.DeadLetterInteractor.ReplayDeadLetter, http.StatusAccepted, "dead letter queued for replay")
+}
+
+// DiscardDeadLetter godoc
+// @Summary Discard a dead-lettered event
+// @Description Give up on a dead-lettered event, so the later events for the same book that were held back behind it are published.
+// @Tags admin
+// @Param id path int true "Dead letter ID"
+// @Success 200 "Dead letter discarded"
+// @Failure 400 "Invalid ID format"
+// @Failure 404 "Dead letter not found"
+// @Failure 409 "Dead letter was already replayed or discarded"
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /admin/dead-letters/{id}/discard [post]
+func (dc *DeadLetterController) DiscardDeadLetter(g *gin.Context) {
+	dc.resolveDeadLetter(g, dc.DeadLetterInteractor.DiscardDeadLetter, http.StatusOK, "dead letter discarded")
+}
+
+// resolveDeadLetter replays or discards the dead letter named in the path and answers status with message
+func (dc *DeadLetterController) resolveDeadLetter(g *gin.Context, resolve func(ctx context.Context, ID int64) error, status int, message string) {
+	id, err := strconv.ParseInt(g.Param("id"), 10, 64)
+	if err != nil {
+		g.JSON(http.StatusBadRequest, domain.ErrorResponse{
+			Message: "Invalid ID format",
+		})
+		return
+	}
+
+	err = resolve(g.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			g.JSON(http.StatusNotFound, domain.ErrorResponse{
+				Message: fmt.Sprintf("Dead letter for ID %d not found", id),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrDeadLetterResolved) {
+			g.JSON(http.StatusConflict, domain.ErrorResponse{
+				Message: fmt.Sprintf("Dead letter for ID %d was already replayed or discarded", id),
+			})
+			return
+		}
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(status, gin.H{"message": message})
+}
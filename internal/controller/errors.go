@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// concurrentModification answers a write that lost a race with another write to the same book,
+// reporting whether it did
+func concurrentModification(g *gin.Context, err error) bool {
+	if !errors.Is(err, domain.ErrConcurrentModification) {
+		return false
+	}
+	g.JSON(http.StatusConflict, domain.ErrorResponse{
+		Message: "The book was changed by another request, retry",
+	})
+	return true
+}
+
+// requestAborted answers a request whose context ended before it could be served,
+// reporting whether it did
+func requestAborted(g *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		g.JSON(http.StatusGatewayTimeout, domain.ErrorResponse{
+			Message: "Request timed out",
+		})
+	case errors.Is(err, context.Canceled):
+		g.JSON(http.StatusServiceUnavailable, domain.ErrorResponse{
+			Message: "Request was cancelled",
+		})
+	default:
+		return false
+	}
+	return true
+}
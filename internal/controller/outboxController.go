@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxController struct {
+	OutboxRelay OutboxService
+}
+
+func NewOutboxController(outboxService OutboxService) *OutboxController {
+	if outboxService == nil {
+		return nil
+	}
+	return &OutboxController{
+		OutboxRelay: outboxService,
+	}
+}
+
+// GetOutboxStats godoc
+// @Summary Get outbox relay statistics
+// @Description Report how many book events are waiting to be published, how far behind the relay is and its most recent failure.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.OutboxStats
+// @Failure 500  "Internal Server Error"
+// @Failure 503  "Request was cancelled"
+// @Failure 504  "Request timed out"
+// @Router /admin/outbox [get]
+func (oc *OutboxController) GetOutboxStats(g *gin.Context) {
+	stats, err := oc.OutboxRelay.Stats(g.Request.Context())
+	if err != nil {
+		if requestAborted(g, err) {
+			return
+		}
+		g.JSON(http.StatusInternalServerError, domain.ErrorResponse{
+			Message: "Internal Server Error",
+		})
+		return
+	}
+	g.JSON(http.StatusOK, stats)
+}
@@ -2,16 +2,40 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	"github.com/Redarcher9/Books-Management-System/internal/domain"
 )
 
 type (
 	BookService interface {
-		GetBooks(ctx context.Context, offset, limit int) ([]*domain.Book, error)
+		GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookPage, error)
+		GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error)
+		SearchBooks(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchPage, error)
+		SuggestBooks(ctx context.Context, query domain.BookSuggestQuery) (*domain.BookSuggestions, error)
 		GetBookByID(ctx context.Context, ID int) (*domain.Book, error)
+		GetBookAt(ctx context.Context, ID int, at time.Time) (*domain.Book, error)
 		DeleteBookByID(ctx context.Context, ID int) error
 		UpdateBookByID(ctx context.Context, ID int, book domain.Book) error
-		CreateBook(ctx context.Context, book *domain.Book) error
+		CreateBook(ctx context.Context, book *domain.Book, force bool) error
+		MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error)
+	}
+
+	CacheService interface {
+		GetCacheStats(ctx context.Context) (*domain.CacheStats, error)
+		GetCachedBook(ctx context.Context, ID int) (*domain.CachedBook, error)
+		WarmCache(ctx context.Context, count, pageSize int) (*domain.CacheWarmResult, error)
+		FlushCache(ctx context.Context) (*domain.CacheFlushResult, error)
+	}
+
+	OutboxService interface {
+		Stats(ctx context.Context) (*domain.OutboxStats, error)
+	}
+
+	DeadLetterService interface {
+		GetDeadLetters(ctx context.Context, offset, limit int) ([]*domain.DeadLetter, error)
+		GetDeadLetterByID(ctx context.Context, ID int64) (*domain.DeadLetter, error)
+		ReplayDeadLetter(ctx context.Context, ID int64) error
+		DiscardDeadLetter(ctx context.Context, ID int64) error
 	}
 )
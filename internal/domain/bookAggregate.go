@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// BookAggregate is the state of a book rebuilt from its events
+type BookAggregate struct {
+	Book       Book      `json:"book"`
+	Version    int       `json:"version"`
+	Deleted    bool      `json:"deleted"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Apply moves the aggregate to the state after event
+func (a *BookAggregate) Apply(event BookEvent, occurredAt time.Time) {
+	switch e := event.(type) {
+	case BookCreated:
+		a.Book = e.After
+		a.Deleted = false
+	case BookUpdated:
+		a.Book = e.After
+	case BookDeleted:
+		a.Book = e.Before
+		a.Deleted = true
+	case BookMerged:
+		// The event is stored for both books; the source book is gone after it
+		if a.Book.ID == e.Source.ID {
+			a.Book = e.Source
+			a.Deleted = true
+		} else {
+			a.Book = e.After
+		}
+	}
+	a.Version++
+	a.OccurredAt = occurredAt
+}
+
+// Exists reports whether the book was created and not deleted
+func (a *BookAggregate) Exists() bool {
+	return a.Version > 0 && !a.Deleted
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCacheUnavailable is returned when the book cache can not be reached
+var ErrCacheUnavailable = errors.New("book cache is unavailable")
+
+// CacheStatus tells whether a read was served from the cache
+type CacheStatus string
+
+const (
+	CacheHit   CacheStatus = "hit"
+	CacheMiss  CacheStatus = "miss"
+	CacheStale CacheStatus = "stale"
+)
+
+type cacheStatusKey struct{}
+
+// CacheStatusRecorder keeps the cache status of the last cached read made with its context
+type CacheStatusRecorder struct {
+	mu     sync.Mutex
+	status CacheStatus
+}
+
+// WithCacheStatusRecorder returns a context whose cached reads are recorded in the returned recorder
+func WithCacheStatusRecorder(ctx context.Context) (context.Context, *CacheStatusRecorder) {
+	recorder := &CacheStatusRecorder{}
+	return context.WithValue(ctx, cacheStatusKey{}, recorder), recorder
+}
+
+// RecordCacheStatus stores status in the recorder of ctx, if it has one
+func RecordCacheStatus(ctx context.Context, status CacheStatus) {
+	recorder, ok := ctx.Value(cacheStatusKey{}).(*CacheStatusRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.status = status
+}
+
+// Status returns the recorded status, or "" when nothing was read through the cache
+func (r *CacheStatusRecorder) Status() CacheStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// CacheStats describes the book cache keyspace
+type CacheStats struct {
+	ListVersion  int64 `json:"list_version"`
+	Keys         int   `json:"keys"`
+	BookKeys     int   `json:"book_keys"`
+	ListKeys     int   `json:"list_keys"`
+	CurrentLists int   `json:"current_list_keys"` // Pages cached under the current list version
+}
+
+// CachedBook is the cache entry stored for a single book
+type CachedBook struct {
+	Key        string     `json:"key"`
+	Cached     bool       `json:"cached"`
+	NotFound   bool       `json:"not_found"` // The book was cached as missing
+	Stale      bool       `json:"stale"`
+	FreshUntil *time.Time `json:"fresh_until,omitempty"`
+	Book       *Book      `json:"book,omitempty"`
+}
+
+type CacheWarmResult struct {
+	Books int `json:"books"`
+	Pages int `json:"pages"`
+}
+
+type CacheFlushResult struct {
+	Keys int `json:"keys"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "errors"
+
+const (
+	CreateBookCommand = "create"
+	UpdateBookCommand = "update"
+	DeleteBookCommand = "delete"
+)
+
+// ErrInvalidBookCommand marks commands that can never succeed, so they are dead-lettered instead of retried
+var ErrInvalidBookCommand = errors.New("invalid book command")
+
+// BookCommand is a create, update or delete request received from the command topic
+type BookCommand struct {
+	Type string `json:"type" example:"update"`
+	ID   int    `json:"id,omitempty" example:"1"`
+	Book *Book  `json:"book,omitempty"`
+	// Force creates the book even if it looks like an existing book, like force=true on POST /books
+	Force bool `json:"force,omitempty"`
+}
@@ -1,5 +1,14 @@
 package domain
 
+import "errors"
+
+// ErrNotSupported is returned when the configured repository cannot serve a request
+var ErrNotSupported = errors.New("not supported by the configured repository")
+
+// ErrConcurrentModification is returned when another request changed a book while it was being written.
+// The write did not happen and may be retried.
+var ErrConcurrentModification = errors.New("book was changed by another request")
+
 type ErrorResponse struct {
 	Message string `json:"message" example:"error message description"`
 }
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrDeadLetterResolved is returned when a dead letter that was already replayed or discarded is resolved again
+var ErrDeadLetterResolved = errors.New("dead letter was already replayed or discarded")
+
+// DeadLetter is an outbox message that ran out of publish attempts.
+// Until it is replayed or discarded, later events for the same book stay in the outbox.
+type DeadLetter struct {
+	ID             int64           `json:"id" example:"1"`
+	OutboxID       int64           `json:"outbox_id" example:"10"`
+	AggregateID    int             `json:"aggregate_id" example:"42"`
+	Topic          string          `json:"topic" example:"book_events"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Error          string          `json:"error" example:"kafka: broker not available"`
+	Attempts       int             `json:"attempts" example:"10"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeadLetteredAt time.Time       `json:"dead_lettered_at"`
+	ReplayedAt     *time.Time      `json:"replayed_at,omitempty"`
+	DiscardedAt    *time.Time      `json:"discarded_at,omitempty"`
+}
+
+// Resolved reports whether the dead letter was replayed or discarded
+func (d *DeadLetter) Resolved() bool {
+	return d.ReplayedAt != nil || d.DiscardedAt != nil
+}
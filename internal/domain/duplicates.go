@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DuplicateCandidate is an existing book that looks like the same book as a new one
+type DuplicateCandidate struct {
+	Book  Book    `json:"book"`
+	Score float64 `json:"score" example:"0.95"` // 1 means title and author are the same once canonicalized
+}
+
+// DuplicateCandidatesError rejects a new book that looks like the existing Candidates, best match first.
+// The book can still be created by forcing it.
+type DuplicateCandidatesError struct {
+	Candidates []DuplicateCandidate
+}
+
+func (e *DuplicateCandidatesError) Error() string {
+	return fmt.Sprintf("%d possible duplicate books found", len(e.Candidates))
+}
+
+type DuplicateResponse struct {
+	Message    string               `json:"message" example:"Possible duplicate books found, retry with force=true to create the book anyway"`
+	Candidates []DuplicateCandidate `json:"candidates"`
+}
+
+// articles are ignored when comparing titles, wherever they appear ("The Hobbit", "Hobbit, The")
+var articles = map[string]bool{"the": true, "a": true, "an": true}
+
+// BookSimilarity scores how likely a and b are the same book, from 0 to 1.
+// It is the mean of the trigram similarities of their canonical titles and authors.
+func BookSimilarity(a, b Book) float64 {
+	titleSimilarity := trigramSimilarity(canonicalTitle(a.Title), canonicalTitle(b.Title))
+	authorSimilarity := trigramSimilarity(canonicalAuthor(a.Author), canonicalAuthor(b.Author))
+	return (titleSimilarity + authorSimilarity) / 2
+}
+
+// TitleSimilarity compares the words of two titles like the pg_trgm similarity of their normalized forms,
+// for repositories that search similar titles without pg_trgm
+func TitleSimilarity(a, b string) float64 {
+	return trigramSimilarity(strings.Join(canonicalWords(a), " "), strings.Join(canonicalWords(b), " "))
+}
+
+// WordSimilarity compares a with the most similar run of words in b like the pg_trgm word_similarity
+// of their normalized forms, so a word being typed matches the words it starts, typos included
+func WordSimilarity(a, b string) float64 {
+	want := trigrams(strings.Join(canonicalWords(a), " "))
+	if len(want) == 0 {
+		return 0
+	}
+
+	// Every extent of the trigrams of b, in order, is compared with all the trigrams of a
+	var ordered []string
+	for _, word := range canonicalWords(b) {
+		ordered = append(ordered, wordTrigrams(word)...)
+	}
+	best := 0.0
+	for i := range ordered {
+		extent := make(map[string]bool)
+		shared := 0
+		for _, trigram := range ordered[i:] {
+			if extent[trigram] {
+				continue
+			}
+			extent[trigram] = true
+			if want[trigram] {
+				shared++
+			}
+			best = max(best, float64(shared)/float64(len(want)+len(extent)-shared))
+		}
+	}
+	return best
+}
+
+// canonicalTitle drops punctuation and articles from the normalized title
+func canonicalTitle(title string) string {
+	var words []string
+	for _, word := range canonicalWords(title) {
+		if !articles[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// canonicalAuthor drops punctuation and sorts the words of the normalized author,
+// so that "J.R.R. Tolkien" and "Tolkien, J. R. R." are the same author
+func canonicalAuthor(author string) string {
+	words := canonicalWords(author)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// canonicalWords splits s into its letter and digit runs, so initials such as "J.R.R." become separate words
+func canonicalWords(s string) []string {
+	return strings.FieldsFunc(NormalizeText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigramSimilarity compares the trigrams of the words of a and b like pg_trgm does:
+// the number of shared trigrams divided by the number of distinct trigrams of both
+func trigramSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	if len(trigramsA) == 0 || len(trigramsB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(trigramsA)+len(trigramsB)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		for _, trigram := range wordTrigrams(word) {
+			result[trigram] = true
+		}
+	}
+	return result
+}
+
+// wordTrigrams returns the trigrams of word in order, padded like pg_trgm with two spaces before and one after
+func wordTrigrams(word string) []string {
+	padded := []rune("  " + word + " ")
+	result := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		result = append(result, string(padded[i:i+3]))
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CloudEventsSpecVersion is the CloudEvents specification the envelope follows
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType marks a message whose whole value is a structured CloudEvents envelope
+	CloudEventsContentType = "application/cloudevents+json"
+	// BookEventSchemaVersion is bumped on breaking changes to the event data; consumers reject other major versions
+	BookEventSchemaVersion = "1.0"
+	// BookEventSource identifies this service as the producer of book events
+	BookEventSource = "/books-management-system/books"
+
+	BookCreatedEventType = "com.books-management-system.book.created"
+	BookUpdatedEventType = "com.books-management-system.book.updated"
+	BookDeletedEventType = "com.books-management-system.book.deleted"
+	BookMergedEventType  = "com.books-management-system.book.merged"
+)
+
+var (
+	ErrUnknownEventType          = errors.New("unknown book event type")
+	ErrUnsupportedSchemaVersion  = errors.New("unsupported book event schema version")
+	ErrUnsupportedCloudEventSpec = errors.New("unsupported cloudevents spec version")
+)
+
+// BookEvent is implemented by every event published on the book events topic
+type BookEvent interface {
+	EventType() string
+	BookID() int
+}
+
+// BookCreated is published after a book is inserted
+type BookCreated struct {
+	After Book `json:"after"`
+}
+
+func (e BookCreated) EventType() string { return BookCreatedEventType }
+func (e BookCreated) BookID() int       { return e.After.ID }
+
+// BookUpdated is published after a book is changed, carrying the record before and after the change
+type BookUpdated struct {
+	Before Book `json:"before"`
+	After  Book `json:"after"`
+}
+
+func (e BookUpdated) EventType() string { return BookUpdatedEventType }
+func (e BookUpdated) BookID() int       { return e.After.ID }
+
+// BookDeleted is published after a book is removed, carrying the last known record
+type BookDeleted struct {
+	Before Book `json:"before"`
+}
+
+func (e BookDeleted) EventType() string { return BookDeletedEventType }
+func (e BookDeleted) BookID() int       { return e.Before.ID }
+
+// BookMerged is published after the source book is merged into the target book and removed,
+// carrying the target before and after the merge and the last known source record
+type BookMerged struct {
+	Before Book `json:"before"`
+	After  Book `json:"after"`
+	Source Book `json:"source"`
+}
+
+func (e BookMerged) EventType() string { return BookMergedEventType }
+func (e BookMerged) BookID() int       { return e.After.ID }
+
+// CloudEvent is a CloudEvents 1.0 envelope in structured JSON mode.
+// SchemaVersion is an extension attribute describing the version of Data.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent wraps a book event in a new envelope
+func NewCloudEvent(event BookEvent) (*CloudEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              newEventID(),
+		Source:          BookEventSource,
+		Type:            event.EventType(),
+		Subject:         strconv.Itoa(event.BookID()),
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		SchemaVersion:   BookEventSchemaVersion,
+		Data:            data,
+	}, nil
+}
+
+// DecodeCloudEvent parses a structured mode envelope and its book event
+func DecodeCloudEvent(payload []byte) (*CloudEvent, BookEvent, error) {
+	var envelope CloudEvent
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, nil, err
+	}
+	event, err := envelope.Decode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &envelope, event, nil
+}
+
+// Decode returns the typed book event carried by the envelope.
+// Events with a newer minor schema version are accepted, unknown fields are ignored.
+func (e *CloudEvent) Decode() (BookEvent, error) {
+	if e.SpecVersion != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCloudEventSpec, e.SpecVersion)
+	}
+	if majorVersion(e.SchemaVersion) != majorVersion(BookEventSchemaVersion) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedSchemaVersion, e.SchemaVersion)
+	}
+
+	var event BookEvent
+	var err error
+	switch e.Type {
+	case BookCreatedEventType:
+		var created BookCreated
+		err = json.Unmarshal(e.Data, &created)
+		event = created
+	case BookUpdatedEventType:
+		var updated BookUpdated
+		err = json.Unmarshal(e.Data, &updated)
+		event = updated
+	case BookDeletedEventType:
+		var deleted BookDeleted
+		err = json.Unmarshal(e.Data, &deleted)
+		event = deleted
+	case BookMergedEventType:
+		var merged BookMerged
+		err = json.Unmarshal(e.Data, &merged)
+		event = merged
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, e.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// newEventID returns a random (version 4) UUID
+func newEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Errorf("failed to generate event ID: %w", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
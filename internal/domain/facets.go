@@ -0,0 +1,57 @@
+package domain
+
+// MaxFacetAuthors bounds the authors counted by a single request
+const MaxFacetAuthors = 100
+
+// BookFacets counts the books matching the filters of a book query by author, decade and year,
+// which is the response of GET /books/facets
+type BookFacets struct {
+	Total   int64         `json:"total" example:"6"`
+	Authors []AuthorCount `json:"authors"` // Most books first, and at most the number asked for
+	Decades []DecadeCount `json:"decades"` // Oldest first
+	Years   []YearCount   `json:"years"`   // Oldest first
+}
+
+// AuthorCount is how many books an author has, ignoring case and spacing
+type AuthorCount struct {
+	Author string `json:"author" example:"J.R.R. Tolkien"`
+	Books  int64  `json:"books" example:"3"`
+}
+
+// DecadeCount is how many books were published in the ten years from Decade
+type DecadeCount struct {
+	Decade int   `json:"decade" example:"1930"`
+	Books  int64 `json:"books" example:"1"`
+}
+
+// YearCount is how many books were published in Year
+type YearCount struct {
+	Year  int   `json:"year" example:"1937"`
+	Books int64 `json:"books" example:"1"`
+}
+
+// NewBookFacets returns the facets of the books counted by author and by year, in order,
+// adding up the years into the decades and the total
+func NewBookFacets(authors []AuthorCount, years []YearCount) *BookFacets {
+	facets := &BookFacets{
+		Authors: authors,
+		Decades: []DecadeCount{},
+		Years:   years,
+	}
+	if facets.Authors == nil {
+		facets.Authors = []AuthorCount{}
+	}
+	if facets.Years == nil {
+		facets.Years = []YearCount{}
+	}
+	for _, year := range years {
+		facets.Total += year.Books
+		decade := year.Year / 10 * 10
+		if n := len(facets.Decades); n > 0 && facets.Decades[n-1].Decade == decade {
+			facets.Decades[n-1].Books += year.Books
+			continue
+		}
+		facets.Decades = append(facets.Decades, DecadeCount{Decade: decade, Books: year.Books})
+	}
+	return facets
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	MergeKeepTarget = "target"
+	MergeKeepSource = "source"
+)
+
+var ErrInvalidMerge = errors.New("invalid book merge")
+
+// BookMergeRequest merges the source book into the target book. For every field it names
+// the book whose value survives, keeping the target's value when empty.
+type BookMergeRequest struct {
+	SourceID int    `json:"source_id" example:"43"`
+	Title    string `json:"title,omitempty" enums:"target,source" example:"source"`
+	Author   string `json:"author,omitempty" enums:"target,source" example:"target"`
+	Year     string `json:"year,omitempty" enums:"target,source" example:"target"`
+}
+
+// Validate checks the request for merging into the book with ID targetID
+func (r BookMergeRequest) Validate(targetID int) error {
+	if r.SourceID <= 0 {
+		return fmt.Errorf("%w: source_id is required", ErrInvalidMerge)
+	}
+	if r.SourceID == targetID {
+		return fmt.Errorf("%w: a book can not be merged into itself", ErrInvalidMerge)
+	}
+	fields := []struct{ name, keep string }{{"title", r.Title}, {"author", r.Author}, {"year", r.Year}}
+	for _, field := range fields {
+		if field.keep != "" && field.keep != MergeKeepTarget && field.keep != MergeKeepSource {
+			return fmt.Errorf("%w: %s must be %q or %q", ErrInvalidMerge, field.name, MergeKeepTarget, MergeKeepSource)
+		}
+	}
+	return nil
+}
+
+// Merge returns the target book with the fields taken from source that the request asks for
+func (r BookMergeRequest) Merge(target, source Book) Book {
+	merged := target
+	if r.Title == MergeKeepSource {
+		merged.Title = source.Title
+	}
+	if r.Author == MergeKeepSource {
+		merged.Author = source.Author
+	}
+	if r.Year == MergeKeepSource {
+		merged.Year = source.Year
+	}
+	return merged
+}
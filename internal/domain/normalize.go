@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeText folds s for duplicate detection: Unicode NFC, lower case,
+// trimmed and with every run of whitespace collapsed to a single space
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFC.String(s))), " ")
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BookEventsTopic is the topic every book change is published to
+const BookEventsTopic = "book_events"
+
+// OutboxMessage is an event waiting in the outbox to be relayed to the event bus
+type OutboxMessage struct {
+	ID          int64           `json:"id"`
+	AggregateID int             `json:"aggregate_id"`
+	Topic       string          `json:"topic"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// OutboxStats describes how far the relay is behind the outbox
+type OutboxStats struct {
+	Pending            int64      `json:"pending" example:"3"`
+	OldestPendingAt    *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds         float64    `json:"lag_seconds" example:"1.5"`
+	Published          int64      `json:"published" example:"120"`
+	Failed             int64      `json:"failed" example:"2"`
+	DeadLettered       int64      `json:"dead_lettered" example:"1"`
+	DeadLettersPending int64      `json:"dead_letters_pending" example:"1"`
+	LastError          string     `json:"last_error,omitempty"`
+	LastErrorAt        *time.Time `json:"last_error_at,omitempty"`
+}
+
+// RelayResult counts what happened to a batch of outbox messages
+type RelayResult struct {
+	Published    int
+	Failed       int
+	DeadLettered int
+}
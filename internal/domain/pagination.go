@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// BookCursor marks where a page of books starts: right after, or right before, the book on the edge of
+// a neighbouring page. Only the fields the query sorts by are used.
+type BookCursor struct {
+	Book   Book
+	Before bool
+}
+
+// BookList is a page of books read by a repository, in the order of the query, and whether
+// more books follow it in the direction it was read
+type BookList struct {
+	Books []*Book `json:"books"`
+	More  bool    `json:"more"`
+}
+
+// BookPage is the response of GET /books
+type BookPage struct {
+	Books      []*Book `json:"books"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"eyJxIjoiNjE0ZjQ3In0"`
+	PrevCursor string  `json:"prev_cursor,omitempty" example:"eyJxIjoiNjE0ZjQ3IiwiYiI6dHJ1ZX0"`
+	Total      *int64  `json:"total,omitempty" example:"42"`
+}
+
+// encodedCursor is the opaque cursor handed to clients. Query fingerprints the filters and sort
+// the cursor was made for, since its position means nothing in another order.
+type encodedCursor struct {
+	Query  string `json:"q"`
+	Before bool   `json:"b,omitempty"`
+	ID     int    `json:"i"`
+	Title  string `json:"t,omitempty"`
+	Author string `json:"a,omitempty"`
+	Year   int    `json:"y,omitempty"`
+}
+
+// EncodeBookCursor returns the opaque form of cursor for the normalized query
+func EncodeBookCursor(query BookQuery, cursor BookCursor) string {
+	encoded := encodedCursor{
+		Query:  fingerprint(query.FilterKey()),
+		Before: cursor.Before,
+		ID:     cursor.Book.ID,
+	}
+	// Only keep the fields the query sorts by
+	for _, sort := range query.Sort {
+		switch sort.Field {
+		case BookSortTitle:
+			encoded.Title = cursor.Book.Title
+		case BookSortAuthor:
+			encoded.Author = cursor.Book.Author
+		case BookSortYear:
+			encoded.Year = cursor.Book.Year
+		}
+	}
+	data, _ := json.Marshal(encoded)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBookCursor parses a cursor returned by EncodeBookCursor for a query with the same filters and sort
+func DecodeBookCursor(s string, query BookQuery) (*BookCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor is malformed", ErrInvalidBookQuery)
+	}
+	var encoded encodedCursor
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: cursor is malformed", ErrInvalidBookQuery)
+	}
+	if encoded.Query != fingerprint(query.Normalize().FilterKey()) {
+		return nil, fmt.Errorf("%w: cursor was made for other filters or another sort", ErrInvalidBookQuery)
+	}
+	return &BookCursor{
+		Book: Book{
+			ID:     encoded.ID,
+			Title:  encoded.Title,
+			Author: encoded.Author,
+			Year:   encoded.Year,
+		},
+		Before: encoded.Before,
+	}, nil
+}
+
+// fingerprint identifies the query a cursor was made for, without carrying the whole query
+func fingerprint(key string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return strconv.FormatUint(hash.Sum64(), 36)
+}
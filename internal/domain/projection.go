@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// StreamMessage is a raw message read back from the event stream
+type StreamMessage struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Value     []byte
+}
+
+// ReplayFrom chooses where a replay starts in each partition.
+// When neither Offset nor Time is set the replay resumes from the projection's checkpoint.
+type ReplayFrom struct {
+	Offset *int64
+	Time   *time.Time
+}
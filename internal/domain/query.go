@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fields books can be sorted by
+const (
+	BookSortID     = "id"
+	BookSortTitle  = "title"
+	BookSortAuthor = "author"
+	BookSortYear   = "year"
+)
+
+// MaxBookQueryIDs bounds the ids filter of a single request
+const MaxBookQueryIDs = 100
+
+var ErrInvalidBookQuery = errors.New("invalid book query")
+
+// BookQuery selects, orders and pages the books listed by GET /books. Empty fields do not filter.
+type BookQuery struct {
+	Author   string // Matched exactly, ignoring case and spacing
+	Title    string // Matched as a substring, ignoring case and spacing
+	YearFrom int    // Inclusive
+	YearTo   int    // Inclusive
+	IDs      []int
+	Sort     []BookSort
+	Cursor   *BookCursor // Where the page starts, or the first page when nil
+	Limit    int
+	Total    bool // Count every book matching the filters as well
+}
+
+// BookSort orders books by one field
+type BookSort struct {
+	Field      string
+	Descending bool
+}
+
+// Value returns the value book is sorted by: its ID or year, or its normalized title or author
+func (s BookSort) Value(book Book) interface{} {
+	switch s.Field {
+	case BookSortTitle:
+		return NormalizeText(book.Title)
+	case BookSortAuthor:
+		return NormalizeText(book.Author)
+	case BookSortYear:
+		return book.Year
+	default:
+		return book.ID
+	}
+}
+
+func (s BookSort) String() string {
+	if s.Descending {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseBookSort parses a comma separated list of fields, each prefixed with "-" to sort descending, like "-year,title"
+func ParseBookSort(s string) []BookSort {
+	var sorts []BookSort
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		sort := BookSort{Field: strings.TrimPrefix(field, "-")}
+		sort.Descending = sort.Field != field
+		sorts = append(sorts, sort)
+	}
+	return sorts
+}
+
+// Validate checks the filters and sort of the query
+func (q BookQuery) Validate() error {
+	if q.YearFrom != 0 && q.YearTo != 0 && q.YearFrom > q.YearTo {
+		return fmt.Errorf("%w: year_from must not be after year_to", ErrInvalidBookQuery)
+	}
+	if len(q.IDs) > MaxBookQueryIDs {
+		return fmt.Errorf("%w: at most %d ids can be requested", ErrInvalidBookQuery, MaxBookQueryIDs)
+	}
+	for _, ID := range q.IDs {
+		if ID <= 0 {
+			return fmt.Errorf("%w: ids must be positive", ErrInvalidBookQuery)
+		}
+	}
+
+	seen := make(map[string]bool, len(q.Sort))
+	for _, sort := range q.Sort {
+		switch sort.Field {
+		case BookSortID, BookSortTitle, BookSortAuthor, BookSortYear:
+		default:
+			return fmt.Errorf("%w: books can not be sorted by %q", ErrInvalidBookQuery, sort.Field)
+		}
+		if seen[sort.Field] {
+			return fmt.Errorf("%w: books are sorted by %s twice", ErrInvalidBookQuery, sort.Field)
+		}
+		seen[sort.Field] = true
+	}
+	return nil
+}
+
+// Normalize returns the query with its text filters normalized, its IDs sorted without duplicates,
+// and its sort ending with the ID, so that equal queries look the same and pages are stable
+func (q BookQuery) Normalize() BookQuery {
+	q.Author = NormalizeText(q.Author)
+	q.Title = NormalizeText(q.Title)
+	if len(q.IDs) > 0 {
+		IDs := slices.Clone(q.IDs)
+		slices.Sort(IDs)
+		q.IDs = slices.Compact(IDs)
+	}
+
+	// The ID is unique, so any field after it changes nothing
+	sorts := make([]BookSort, 0, len(q.Sort)+1)
+	for _, sort := range q.Sort {
+		sorts = append(sorts, sort)
+		if sort.Field == BookSortID {
+			break
+		}
+	}
+	if len(sorts) == 0 || sorts[len(sorts)-1].Field != BookSortID {
+		sorts = append(sorts, BookSort{Field: BookSortID})
+	}
+	q.Sort = sorts
+	return q
+}
+
+// FilterKey encodes the filters and sort of the normalized query, leaving out the page, so that
+// equal queries have equal keys however their parameters were written. A query without a sort,
+// as for facets, leaves it out too.
+func (q BookQuery) FilterKey() string {
+	params := url.Values{}
+	if q.Author != "" {
+		params.Set("author", q.Author)
+	}
+	if q.Title != "" {
+		params.Set("title", q.Title)
+	}
+	if q.YearFrom != 0 {
+		params.Set("year_from", strconv.Itoa(q.YearFrom))
+	}
+	if q.YearTo != 0 {
+		params.Set("year_to", strconv.Itoa(q.YearTo))
+	}
+	if len(q.IDs) > 0 {
+		IDs := make([]string, 0, len(q.IDs))
+		for _, ID := range q.IDs {
+			IDs = append(IDs, strconv.Itoa(ID))
+		}
+		params.Set("ids", strings.Join(IDs, ","))
+	}
+	if len(q.Sort) > 0 {
+		sorts := make([]string, 0, len(q.Sort))
+		for _, sort := range q.Sort {
+			sorts = append(sorts, sort.String())
+		}
+		params.Set("sort", strings.Join(sorts, ","))
+	}
+	return params.Encode()
+}
+
+// Unfiltered reports whether the normalized query lists every book in ID order
+func (q BookQuery) Unfiltered() bool {
+	return q.Author == "" && q.Title == "" && q.YearFrom == 0 && q.YearTo == 0 && len(q.IDs) == 0 &&
+		len(q.Sort) == 1 && q.Sort[0] == BookSort{Field: BookSortID}
+}
+
+// Matches reports whether book passes the filters of the normalized query
+func (q BookQuery) Matches(book Book) bool {
+	if q.Author != "" && NormalizeText(book.Author) != q.Author {
+		return false
+	}
+	if q.Title != "" && !strings.Contains(NormalizeText(book.Title), q.Title) {
+		return false
+	}
+	if q.YearFrom != 0 && book.Year < q.YearFrom {
+		return false
+	}
+	if q.YearTo != 0 && book.Year > q.YearTo {
+		return false
+	}
+	if len(q.IDs) > 0 && !slices.Contains(q.IDs, book.ID) {
+		return false
+	}
+	return true
+}
+
+// Compare orders books by the sort of the query, comparing titles and authors normalized
+func (q BookQuery) Compare(a, b Book) int {
+	for _, sort := range q.Sort {
+		var c int
+		switch aValue, bValue := sort.Value(a), sort.Value(b); aValue := aValue.(type) {
+		case int:
+			c = aValue - bValue.(int)
+		case string:
+			c = strings.Compare(aValue, bValue.(string))
+		}
+		if sort.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy spaces out retries with exponential backoff and gives up after MaxAttempts
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Exhausted reports whether a message that failed attempts times should be dead-lettered
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// Backoff returns the delay before the next try after attempts failures.
+// The delay doubles per attempt up to MaxDelay, or the longest time.Duration without it, and half of it is randomised
+// so that failures for many books do not retry in lockstep.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if p.BaseDelay <= 0 || attempts <= 0 {
+		return 0
+	}
+
+	// Without MaxDelay the doubling stops before it would overflow
+	delay := p.BaseDelay
+	for i := 1; i < attempts && (p.MaxDelay <= 0 || delay < p.MaxDelay) && delay <= math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
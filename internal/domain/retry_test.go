@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   domain.RetryPolicy
+		attempts int
+		min, max time.Duration
+	}{
+		{name: "first retry", policy: domain.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, attempts: 1, min: time.Second / 2, max: time.Second},
+		{name: "doubled", policy: domain.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, attempts: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "capped", policy: domain.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, attempts: 100, min: 30 * time.Second, max: time.Minute},
+		{name: "uncapped without overflow", policy: domain.RetryPolicy{BaseDelay: time.Second}, attempts: 100, min: time.Duration(1 << 61), max: time.Duration(1<<63 - 1)},
+		{name: "no base delay", policy: domain.RetryPolicy{MaxDelay: time.Minute}, attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.policy.Backoff(tt.attempts); got < tt.min || got > tt.max {
+					t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempts, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxBookSearchLength bounds the text of a full-text search
+const MaxBookSearchLength = 255
+
+// BookSearchQuery is a full-text search of titles and authors, most relevant books first
+type BookSearchQuery struct {
+	Text   string
+	Cursor *BookSearchCursor // Where the page starts, or the first page when nil
+	Limit  int
+	Total  bool // Count every matching book as well
+}
+
+// BookSearchCursor marks where a page of search results starts: right after, or right before,
+// the result with this rank and book ID
+type BookSearchCursor struct {
+	Rank   float64
+	ID     int
+	Before bool
+}
+
+// BookSearchHit is a book matching a search. The highlights are the HTML escaped title and author
+// with the matching words wrapped in <mark> elements.
+type BookSearchHit struct {
+	Book
+	Rank            float64 `json:"rank" example:"0.0607927"`
+	TitleHighlight  string  `json:"title_highlight" example:"The <mark>Hobbit</mark>"`
+	AuthorHighlight string  `json:"author_highlight" example:"J.R.R. Tolkien"`
+}
+
+// BookSearchList is a page of search results read by a repository, in rank order, and whether
+// more results follow it in the direction it was read
+type BookSearchList struct {
+	Hits []*BookSearchHit `json:"hits"`
+	More bool             `json:"more"`
+}
+
+// BookSearchPage is the response of GET /books/search
+type BookSearchPage struct {
+	Books      []*BookSearchHit `json:"books"`
+	NextCursor string           `json:"next_cursor,omitempty" example:"eyJxIjoiMWtzZTV6YW1sIiwiciI6MC4wNiwiaSI6N30"`
+	PrevCursor string           `json:"prev_cursor,omitempty"`
+	Total      *int64           `json:"total,omitempty" example:"3"`
+}
+
+// Validate checks the search text
+func (q BookSearchQuery) Validate() error {
+	if NormalizeText(q.Text) == "" {
+		return fmt.Errorf("%w: q is required", ErrInvalidBookQuery)
+	}
+	if len(q.Text) > MaxBookSearchLength {
+		return fmt.Errorf("%w: q must be at most %d bytes", ErrInvalidBookQuery, MaxBookSearchLength)
+	}
+	return nil
+}
+
+// Normalize returns the query with its text normalized, so that equal searches look the same
+func (q BookSearchQuery) Normalize() BookSearchQuery {
+	q.Text = NormalizeText(q.Text)
+	return q
+}
+
+// Compare orders search results by descending rank, breaking ties by ID
+func (c BookSearchCursor) Compare(rank float64, ID int) int {
+	switch {
+	case rank > c.Rank:
+		return -1
+	case rank < c.Rank:
+		return 1
+	default:
+		return ID - c.ID
+	}
+}
+
+type encodedSearchCursor struct {
+	Query  string  `json:"q"`
+	Before bool    `json:"b,omitempty"`
+	Rank   float64 `json:"r"`
+	ID     int     `json:"i"`
+}
+
+// EncodeBookSearchCursor returns the opaque form of cursor for the normalized query
+func EncodeBookSearchCursor(query BookSearchQuery, cursor BookSearchCursor) string {
+	data, _ := json.Marshal(encodedSearchCursor{
+		Query:  fingerprint(query.Text),
+		Before: cursor.Before,
+		Rank:   cursor.Rank,
+		ID:     cursor.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBookSearchCursor parses a cursor returned by EncodeBookSearchCursor for a query with the same text
+func DecodeBookSearchCursor(s string, query BookSearchQuery) (*BookSearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor is malformed", ErrInvalidBookQuery)
+	}
+	var encoded encodedSearchCursor
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: cursor is malformed", ErrInvalidBookQuery)
+	}
+	if encoded.Query != fingerprint(query.Normalize().Text) {
+		return nil, fmt.Errorf("%w: cursor was made for another search", ErrInvalidBookQuery)
+	}
+	return &BookSearchCursor{
+		Rank:   encoded.Rank,
+		ID:     encoded.ID,
+		Before: encoded.Before,
+	}, nil
+}
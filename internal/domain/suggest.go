@@ -0,0 +1,48 @@
+package domain
+
+import "fmt"
+
+// Fields a book suggestion completes
+const (
+	BookSuggestionTitle  = "title"
+	BookSuggestionAuthor = "author"
+)
+
+// MaxBookSuggestions bounds the suggestions of a single request
+const MaxBookSuggestions = 20
+
+// BookSuggestQuery asks for the titles and authors that complete, or nearly complete, what is being typed
+type BookSuggestQuery struct {
+	Prefix string
+	Limit  int
+}
+
+// BookSuggestion is a title or an author completing a suggest query, and how many books have it
+type BookSuggestion struct {
+	Field string `json:"field" example:"title" enums:"title,author"`
+	Text  string `json:"text" example:"The Hobbit"`
+	Books int64  `json:"books" example:"1"`
+}
+
+// BookSuggestions is the response of GET /books/suggest, best completion first
+type BookSuggestions struct {
+	Suggestions []*BookSuggestion `json:"suggestions"`
+}
+
+// Validate checks the prefix
+func (q BookSuggestQuery) Validate() error {
+	if NormalizeText(q.Prefix) == "" {
+		return fmt.Errorf("%w: prefix is required", ErrInvalidBookQuery)
+	}
+	if len(q.Prefix) > MaxBookSearchLength {
+		return fmt.Errorf("%w: prefix must be at most %d bytes", ErrInvalidBookQuery, MaxBookSearchLength)
+	}
+	return nil
+}
+
+// Normalize returns the query with its prefix normalized and its limit within bounds
+func (q BookSuggestQuery) Normalize() BookSuggestQuery {
+	q.Prefix = NormalizeText(q.Prefix)
+	q.Limit = min(max(q.Limit, 1), MaxBookSuggestions)
+	return q
+}
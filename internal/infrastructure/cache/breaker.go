@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Breaker stops calling a failing cache. After threshold consecutive failures every call
+// fails fast with ErrCircuitOpen for cooldown, after which a single call is let through
+// to probe the cache and either close the circuit again or reopen it.
+type Breaker struct {
+	next      Cache
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func NewBreaker(next Cache, threshold int, cooldown time.Duration) *Breaker {
+	if next == nil {
+		return nil
+	}
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 10 * time.Second
+	}
+	return &Breaker{
+		next:      next,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+func (b *Breaker) Get(ctx context.Context, key string) (value []byte, err error) {
+	err = b.do(ctx, func() error {
+		var callErr error
+		value, callErr = b.next.Get(ctx, key)
+		return callErr
+	})
+	return value, err
+}
+
+func (b *Breaker) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.do(ctx, func() error {
+		return b.next.Set(ctx, key, value, ttl)
+	})
+}
+
+func (b *Breaker) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (ok bool, err error) {
+	err = b.do(ctx, func() error {
+		var callErr error
+		ok, callErr = b.next.SetNX(ctx, key, value, ttl)
+		return callErr
+	})
+	return ok, err
+}
+
+func (b *Breaker) Del(ctx context.Context, keys ...string) error {
+	return b.do(ctx, func() error {
+		return b.next.Del(ctx, keys...)
+	})
+}
+
+func (b *Breaker) Incr(ctx context.Context, key string) (value int64, err error) {
+	err = b.do(ctx, func() error {
+		var callErr error
+		value, callErr = b.next.Incr(ctx, key)
+		return callErr
+	})
+	return value, err
+}
+
+func (b *Breaker) Keys(ctx context.Context, pattern string) (keys []string, err error) {
+	err = b.do(ctx, func() error {
+		var callErr error
+		keys, callErr = b.next.Keys(ctx, pattern)
+		return callErr
+	})
+	return keys, err
+}
+
+func (b *Breaker) Publish(ctx context.Context, channel string, message []byte) error {
+	return b.do(ctx, func() error {
+		return b.next.Publish(ctx, channel, message)
+	})
+}
+
+// Subscribe is passed straight through, since subscriptions already reconnect on their own
+func (b *Breaker) Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func()) {
+	b.next.Subscribe(ctx, channel, handle, resync)
+}
+
+// Open reports whether calls are currently failing fast
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold
+}
+
+func (b *Breaker) do(ctx context.Context, call func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := call()
+	b.record(ctx, err)
+	return err
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts a failed call against the cache. Calls that failed because the caller's own context
+// was cancelled or ran past its deadline say nothing about the cache, unlike the client's timeouts.
+func (b *Breaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbing := b.probing
+	b.probing = false
+	switch {
+	case err == nil || errors.Is(err, ErrMiss):
+		if b.failures >= b.threshold {
+			fmt.Println("cache circuit closed")
+		}
+		b.failures = 0
+	case ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// The caller gave up or ran out of time
+	default:
+		b.failures++
+		if b.failures == b.threshold || wasProbing {
+			fmt.Printf("cache circuit open for %s: %s\n", b.cooldown, err)
+			b.openUntil = time.Now().Add(b.cooldown)
+		}
+	}
+}
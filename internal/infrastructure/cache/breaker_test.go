@@ -0,0 +1,45 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache/cachetest"
+)
+
+func TestBreakerIgnoresCallerDeadlines(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expired  bool // the caller's context has ended
+		wantOpen bool
+	}{
+		{name: "cache failure", err: errors.New("i/o timeout"), wantOpen: true},
+		{name: "deadline of the client", err: context.DeadlineExceeded, wantOpen: true},
+		{name: "deadline of the caller", err: context.DeadlineExceeded, expired: true},
+		{name: "caller gave up", err: context.Canceled, expired: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := cachetest.New()
+			next.FailWith(tt.err)
+			breaker := cache.NewBreaker(next, 2, time.Minute)
+
+			ctx := context.Background()
+			if tt.expired {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, time.Now())
+				defer cancel()
+			}
+			for i := 0; i < 2; i++ {
+				breaker.Get(ctx, "books:1")
+			}
+			if breaker.Open() != tt.wantOpen {
+				t.Errorf("circuit open is %t after two failures, want %t", breaker.Open(), tt.wantOpen)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	RedisBackend  = "redis"
+	MemoryBackend = "memory"
+	NoOpBackend   = "none"
+)
+
+// ErrMiss is returned by Get when nothing is cached under the key
+var ErrMiss = errors.New("cache miss")
+
+// ErrCircuitOpen is returned instead of calling a cache that keeps failing
+var ErrCircuitOpen = errors.New("cache circuit open")
+
+// Cache is implemented by every backend in this package
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	Publish(ctx context.Context, channel string, message []byte) error
+	Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func())
+}
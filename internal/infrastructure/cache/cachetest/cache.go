@@ -0,0 +1,107 @@
+// Package cachetest provides a fake of the Redis cache for tests
+package cachetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
+)
+
+// Cache is a fake of the Redis cache. It stores entries and delivers messages like cache.Memory does,
+// and fails every call while FailWith has set an error, like an unreachable Redis.
+type Cache struct {
+	memory *cache.Memory
+
+	mu          sync.Mutex
+	err         error
+	subscribers int
+}
+
+func New() *Cache {
+	return &Cache{memory: cache.NewMemory(0)}
+}
+
+// FailWith makes every call return err until it is called again with nil
+func (c *Cache) FailWith(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *Cache) failure() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := c.failure(); err != nil {
+		return nil, err
+	}
+	return c.memory.Get(ctx, key)
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.failure(); err != nil {
+		return err
+	}
+	return c.memory.Set(ctx, key, value, ttl)
+}
+
+func (c *Cache) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if err := c.failure(); err != nil {
+		return false, err
+	}
+	return c.memory.SetNX(ctx, key, value, ttl)
+}
+
+func (c *Cache) Del(ctx context.Context, keys ...string) error {
+	if err := c.failure(); err != nil {
+		return err
+	}
+	return c.memory.Del(ctx, keys...)
+}
+
+func (c *Cache) Incr(ctx context.Context, key string) (int64, error) {
+	if err := c.failure(); err != nil {
+		return 0, err
+	}
+	return c.memory.Incr(ctx, key)
+}
+
+func (c *Cache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if err := c.failure(); err != nil {
+		return nil, err
+	}
+	return c.memory.Keys(ctx, pattern)
+}
+
+// Publish drops the message while failing, as Redis pub/sub does not keep messages for later
+func (c *Cache) Publish(ctx context.Context, channel string, message []byte) error {
+	if err := c.failure(); err != nil {
+		return err
+	}
+	return c.memory.Publish(ctx, channel, message)
+}
+
+func (c *Cache) Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func()) {
+	c.memory.Subscribe(ctx, channel, handle, func() {
+		c.mu.Lock()
+		c.subscribers++
+		c.mu.Unlock()
+		resync()
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers--
+}
+
+// Subscribers returns the number of active subscriptions. Messages are delivered to them before Publish returns.
+func (c *Cache) Subscribers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscribers
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Memory is a size bounded in-process Cache whose entries expire after their ttl.
+// Messages published on it only reach subscribers in the same process.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+
+	subscribersMu sync.Mutex
+	subscribers   map[string]map[*subscriber]struct{}
+}
+
+type memoryItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type subscriber struct {
+	handle func(message []byte)
+}
+
+// NewMemory returns a cache holding at most capacity entries, or any number when capacity is not positive
+func NewMemory(capacity int) *Memory {
+	return &Memory{
+		capacity:    capacity,
+		order:       list.New(),
+		items:       make(map[string]*list.Element),
+		subscribers: make(map[string]map[*subscriber]struct{}),
+	}
+}
+
+func (c *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.lookup(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memoryItem).value, nil
+}
+
+// Set stores value under key; a ttl of 0 keeps it until it is evicted
+func (c *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(key, value, ttl)
+	return nil
+}
+
+func (c *Memory) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.lookup(key); ok {
+		return false, nil
+	}
+	c.store(key, value, ttl)
+	return true, nil
+}
+
+func (c *Memory) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.removeElement(element)
+		}
+	}
+	return nil
+}
+
+// Incr increments the integer stored under key, keeping its expiry like Redis does
+func (c *Memory) Incr(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.lookup(key)
+	if !ok {
+		c.store(key, []byte("1"), 0)
+		return 1, nil
+	}
+
+	item := element.Value.(*memoryItem)
+	value, err := strconv.ParseInt(string(item.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	value++
+	item.value = []byte(strconv.FormatInt(value, 10))
+	c.order.MoveToFront(element)
+	return value, nil
+}
+
+// Keys lists the live keys matching pattern, using path.Match syntax
+func (c *Memory) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []string
+	for key := range c.items {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.lookup(key); ok && matched {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Purge drops every entry
+func (c *Memory) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *Memory) Publish(ctx context.Context, channel string, message []byte) error {
+	c.subscribersMu.Lock()
+	handlers := make([]func([]byte), 0, len(c.subscribers[channel]))
+	for s := range c.subscribers[channel] {
+		handlers = append(handlers, s.handle)
+	}
+	c.subscribersMu.Unlock()
+
+	for _, handle := range handlers {
+		handle(message)
+	}
+	return nil
+}
+
+// Subscribe hands every message published on channel to handle until ctx is cancelled
+func (c *Memory) Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func()) {
+	s := &subscriber{handle: handle}
+	c.subscribersMu.Lock()
+	if c.subscribers[channel] == nil {
+		c.subscribers[channel] = make(map[*subscriber]struct{})
+	}
+	c.subscribers[channel][s] = struct{}{}
+	c.subscribersMu.Unlock()
+
+	resync()
+	<-ctx.Done()
+
+	c.subscribersMu.Lock()
+	delete(c.subscribers[channel], s)
+	c.subscribersMu.Unlock()
+}
+
+// lookup returns the live element for key, dropping it when it has expired
+func (c *Memory) lookup(key string) (*list.Element, bool) {
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*memoryItem)
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		c.removeElement(element)
+		return nil, false
+	}
+	return element, true
+}
+
+func (c *Memory) store(key string, value []byte, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*memoryItem)
+		item.value = value
+		item.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&memoryItem{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *Memory) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*memoryItem).key)
+}
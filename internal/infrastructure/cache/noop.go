@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoOp caches nothing, so every read goes to the database
+type NoOp struct{}
+
+func (NoOp) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+func (NoOp) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (NoOp) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (NoOp) Del(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+func (NoOp) Incr(ctx context.Context, key string) (int64, error) {
+	return 0, nil
+}
+
+func (NoOp) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return nil, nil
+}
+
+func (NoOp) Publish(ctx context.Context, channel string, message []byte) error {
+	return nil
+}
+
+func (NoOp) Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func()) {
+	<-ctx.Done()
+}
+
+func (NoOp) Purge() {}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Redis is a Cache shared by every instance of the service
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(client *redis.Client) *Redis {
+	if client == nil {
+		return nil
+	}
+	return &Redis{
+		client: client,
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.WithContext(ctx).Get(key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.WithContext(ctx).Set(key, value, ttl).Err()
+}
+
+func (r *Redis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return r.client.WithContext(ctx).SetNX(key, value, ttl).Result()
+}
+
+func (r *Redis) Del(ctx context.Context, keys ...string) error {
+	return r.client.WithContext(ctx).Del(keys...).Err()
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.WithContext(ctx).Incr(key).Result()
+}
+
+// Keys walks the keyspace with SCAN, so Redis is never blocked by a large KEYS call
+func (r *Redis) Keys(ctx context.Context, pattern string) ([]string, error) {
+	client := r.client.WithContext(ctx)
+	var keys []string
+	var cursor uint64
+	for {
+		page, next, err := client.Scan(cursor, pattern, 1000).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+func (r *Redis) Publish(ctx context.Context, channel string, message []byte) error {
+	return r.client.WithContext(ctx).Publish(channel, message).Err()
+}
+
+// Subscribe hands every message on channel to handle until ctx is cancelled.
+// resync is called whenever the subscription is (re)established, because messages
+// sent while disconnected are lost.
+func (r *Redis) Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func()) {
+	pubsub := r.client.Subscribe(channel)
+	defer pubsub.Close()
+	go func() {
+		<-ctx.Done()
+		pubsub.Close()
+	}()
+
+	for {
+		received, err := pubsub.Receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// go-redis reconnects on the next Receive
+			resync()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch msg := received.(type) {
+		case *redis.Subscription:
+			resync()
+		case *redis.Message:
+			handle([]byte(msg.Payload))
+		case error:
+			fmt.Println("cache subscription error:", msg)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Channel delivers events to subscribers in the same process, so no broker is needed
+type Channel struct {
+	mu          sync.RWMutex
+	subscribers map[*subscription]struct{}
+	bufferSize  int
+}
+
+type subscription struct {
+	topic    string
+	messages chan Message
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// stop unblocks any Publish waiting on the subscriber
+func (s *subscription) stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func NewChannel(bufferSize int) *Channel {
+	return &Channel{
+		subscribers: make(map[*subscription]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe returns the events published to topic, or to every topic when topic is empty.
+// The returned function cancels the subscription and closes the channel.
+func (c *Channel) Subscribe(topic string) (<-chan Message, func()) {
+	sub := &subscription{
+		topic:    topic,
+		messages: make(chan Message, c.bufferSize),
+		done:     make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	c.subscribers[sub] = struct{}{}
+	c.mu.Unlock()
+
+	return sub.messages, func() {
+		c.unsubscribe(sub)
+	}
+}
+
+func (c *Channel) unsubscribe(sub *subscription) {
+	sub.stop()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subscribers[sub]; ok {
+		delete(c.subscribers, sub)
+		close(sub.messages)
+	}
+}
+
+// Publish blocks until every matching subscriber accepted the event or ctx is done
+func (c *Channel) Publish(ctx context.Context, topic string, message interface{}) error {
+	msgBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	key, eventType := describe(message)
+	msg := Message{
+		Topic: topic,
+		Key:   key,
+		Type:  eventType,
+		Value: msgBytes,
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for sub := range c.subscribers {
+		if sub.topic != "" && sub.topic != topic {
+			continue
+		}
+		select {
+		case sub.messages <- msg:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close cancels every subscription
+func (c *Channel) Close() error {
+	c.mu.RLock()
+	subscribers := make([]*subscription, 0, len(c.subscribers))
+	for sub := range c.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	c.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		c.unsubscribe(sub)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package eventbus
+
+import (
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+const (
+	KafkaBackend  = "kafka"
+	RedisBackend  = "redis"
+	MemoryBackend = "memory"
+)
+
+// Message is an event as delivered by the in-process and Redis Streams backends
+type Message struct {
+	Topic string
+	Key   string
+	Type  string
+	Value []byte
+}
+
+// describe returns the key and type of message, which are only known for CloudEvents
+func describe(message interface{}) (key, eventType string) {
+	if event, ok := message.(*domain.CloudEvent); ok {
+		return event.Subject, event.Type
+	}
+	return "", ""
+}
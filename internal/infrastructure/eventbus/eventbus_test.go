@@ -0,0 +1,109 @@
+package eventbus_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/eventbus"
+	"github.com/go-redis/redis"
+)
+
+// redisAddrEnv names a Redis server the tests may write streams to, e.g. "localhost:6379"
+const redisAddrEnv = "BOOKS_TEST_REDIS_ADDR"
+
+type bus interface {
+	Publish(ctx context.Context, topic string, message interface{}) error
+}
+
+// TestBackendsKeepBookEventsOnOneTopic checks that every backend delivers all event types for a book
+// on the topic they were published to, in order and tagged with their type
+func TestBackendsKeepBookEventsOnOneTopic(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(t *testing.T, topic string) (bus, func() []eventbus.Message)
+	}{
+		{name: eventbus.MemoryBackend, open: openChannel},
+		{name: eventbus.RedisBackend, open: openRedisStreams},
+	}
+
+	book := domain.Book{ID: 7, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1937}
+	events := []domain.BookEvent{
+		domain.BookCreated{After: book},
+		domain.BookUpdated{Before: book, After: domain.Book{ID: 7, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1951}},
+		domain.BookDeleted{Before: book},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			topic := fmt.Sprintf("%s_%d", domain.BookEventsTopic, time.Now().UnixNano())
+			publisher, read := backend.open(t, topic)
+
+			for _, event := range events {
+				envelope, err := domain.NewCloudEvent(event)
+				if err != nil {
+					t.Fatalf("NewCloudEvent: %v", err)
+				}
+				if err := publisher.Publish(context.Background(), topic, envelope); err != nil {
+					t.Fatalf("Publish: %v", err)
+				}
+			}
+
+			messages := read()
+			if len(messages) != len(events) {
+				t.Fatalf("read %d messages from %s, want %d", len(messages), topic, len(events))
+			}
+			for i, message := range messages {
+				if message.Type != events[i].EventType() || message.Key != "7" {
+					t.Errorf("message %d has type %q and key %q, want %q and %q", i, message.Type, message.Key, events[i].EventType(), "7")
+				}
+			}
+		})
+	}
+}
+
+func openChannel(t *testing.T, topic string) (bus, func() []eventbus.Message) {
+	channel := eventbus.NewChannel(10)
+	t.Cleanup(func() { channel.Close() })
+	messages, cancel := channel.Subscribe(topic)
+
+	return channel, func() []eventbus.Message {
+		cancel()
+		var received []eventbus.Message
+		for message := range messages {
+			received = append(received, message)
+		}
+		return received
+	}
+}
+
+// openRedisStreams writes to the server named by BOOKS_TEST_REDIS_ADDR and skips the test when it is not set
+func openRedisStreams(t *testing.T, topic string) (bus, func() []eventbus.Message) {
+	addr := os.Getenv(redisAddrEnv)
+	if addr == "" {
+		t.Skipf("%s is not set", redisAddrEnv)
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() {
+		client.Del(topic)
+		client.Close()
+	})
+
+	return eventbus.NewRedisStreams(client, 0), func() []eventbus.Message {
+		entries, err := client.XRange(topic, "-", "+").Result()
+		if err != nil {
+			t.Fatalf("XRange: %v", err)
+		}
+		var received []eventbus.Message
+		for _, entry := range entries {
+			key, _ := entry.Values["key"].(string)
+			eventType, _ := entry.Values["type"].(string)
+			value, _ := entry.Values["value"].(string)
+			received = append(received, eventbus.Message{Topic: topic, Key: key, Type: eventType, Value: []byte(value)})
+		}
+		return received
+	}
+}
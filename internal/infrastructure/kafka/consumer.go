@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	retryBackoff    = time.Second
+	maxRetryBackoff = 30 * time.Second
+	// maxHandleAttempts bounds how often a transiently failing message is tried before it is dead-lettered
+	maxHandleAttempts = 5
+)
+
+// MessageHandler processes the value of a single message
+type MessageHandler func(ctx context.Context, value []byte) error
+
+// MessageReader is the part of kafka.Reader the consumer uses
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// MessageWriter is the part of kafka.Writer the consumer uses
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type KafkaConsumer struct {
+	reader     MessageReader
+	deadLetter MessageWriter
+}
+
+func NewKafkaConsumer(brokerAddress, topic, groupID, deadLetterTopic string, security SecurityConfig) (*KafkaConsumer, error) {
+	dialer, err := security.dialer()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := security.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{brokerAddress},
+		Topic:   topic,
+		GroupID: groupID,
+		Dialer:  dialer,
+	})
+	// The source offset is committed once the dead letter is written, so every replica must have it
+	deadLetter := &kafka.Writer{
+		Addr:         kafka.TCP(brokerAddress),
+		Topic:        deadLetterTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}
+	return NewConsumer(reader, deadLetter), nil
+}
+
+// NewConsumer returns a consumer reading from reader and dead-lettering to deadLetter
+func NewConsumer(reader MessageReader, deadLetter MessageWriter) *KafkaConsumer {
+	return &KafkaConsumer{
+		reader:     reader,
+		deadLetter: deadLetter,
+	}
+}
+
+// Run hands every message to handle until ctx is cancelled.
+// Offsets are committed only once a message was handled or dead-lettered.
+func (c *KafkaConsumer) Run(ctx context.Context, handle MessageHandler) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := c.process(ctx, msg, handle); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// process retries transient failures up to maxHandleAttempts times and dead-letters invalid messages
+// and messages that never succeed
+func (c *KafkaConsumer) process(ctx context.Context, msg kafka.Message, handle MessageHandler) error {
+	for attempt := 1; ; attempt++ {
+		err := handle(ctx, msg.Value)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, domain.ErrInvalidBookCommand) || attempt >= maxHandleAttempts {
+			return c.sendToDeadLetter(ctx, msg, err, attempt)
+		}
+
+		fmt.Printf("failed to handle message at offset %d (attempt %d): %s\n", msg.Offset, attempt, err)
+		backoff := min(retryBackoff*time.Duration(attempt), maxRetryBackoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (c *KafkaConsumer) sendToDeadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	fmt.Printf("dead-lettering message at offset %d: %s\n", msg.Offset, cause)
+	return c.deadLetter.WriteMessages(ctx, kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: append(msg.Headers,
+			kafka.Header{Key: "dlq-error", Value: []byte(cause.Error())},
+			kafka.Header{Key: "dlq-attempts", Value: []byte(strconv.Itoa(attempts))},
+			kafka.Header{Key: "dlq-original-topic", Value: []byte(msg.Topic)},
+			kafka.Header{Key: "dlq-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: "dlq-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		),
+	})
+}
+
+func (c *KafkaConsumer) Close() error {
+	return errors.Join(c.reader.Close(), c.deadLetter.Close())
+}
@@ -0,0 +1,82 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/kafka"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// reader hands out its messages once and records the committed offsets
+type reader struct {
+	messages  []kafkago.Message
+	committed []int64
+}
+
+func (r *reader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	if len(r.messages) == 0 {
+		return kafkago.Message{}, context.Canceled
+	}
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+func (r *reader) CommitMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *reader) Close() error { return nil }
+
+// writer fails every write with err and records the rest
+type writer struct {
+	err     error
+	written []kafkago.Message
+}
+
+func (w *writer) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+func (w *writer) Close() error { return nil }
+
+func TestConsumerCommitsOnlyDeadLettersThatWereWritten(t *testing.T) {
+	invalid := func(ctx context.Context, value []byte) error {
+		return fmt.Errorf("%w: missing title", domain.ErrInvalidBookCommand)
+	}
+
+	t.Run("dead letter written", func(t *testing.T) {
+		source := &reader{messages: []kafkago.Message{{Topic: "book_commands", Offset: 7, Value: []byte("{}")}}}
+		deadLetter := &writer{}
+		err := kafka.NewConsumer(source, deadLetter).Run(context.Background(), invalid)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Run returned %v, want the end of the fake reader", err)
+		}
+		if len(deadLetter.written) != 1 || len(source.committed) != 1 || source.committed[0] != 7 {
+			t.Errorf("Run wrote %d dead letters and committed %v, want 1 and [7]", len(deadLetter.written), source.committed)
+		}
+	})
+
+	t.Run("dead letter write failed", func(t *testing.T) {
+		source := &reader{messages: []kafkago.Message{{Topic: "book_commands", Offset: 7, Value: []byte("{}")}}}
+		brokerDown := errors.New("not enough replicas")
+		err := kafka.NewConsumer(source, &writer{err: brokerDown}).Run(context.Background(), invalid)
+		if !errors.Is(err, brokerDown) {
+			t.Fatalf("Run returned %v, want %v", err, brokerDown)
+		}
+		if len(source.committed) != 0 {
+			t.Errorf("Run committed %v, want the offset left uncommitted so the message is read again", source.committed)
+		}
+	})
+}
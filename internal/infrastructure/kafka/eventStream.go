@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaEventStream reads topics partition by partition, outside of any consumer group
+type KafkaEventStream struct {
+	brokerAddress string
+	dialer        *kafka.Dialer
+}
+
+func NewKafkaEventStream(brokerAddress string, security SecurityConfig) (*KafkaEventStream, error) {
+	dialer, err := security.dialer()
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaEventStream{
+		brokerAddress: brokerAddress,
+		dialer:        dialer,
+	}, nil
+}
+
+func (s *KafkaEventStream) Partitions(ctx context.Context, topic string) ([]int, error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.brokerAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(partitions))
+	for _, p := range partitions {
+		ids = append(ids, p.ID)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Read hands messages to handle from start up to the end offset observed when Read was called.
+// Without a start position the partition is read from the beginning.
+func (s *KafkaEventStream) Read(ctx context.Context, topic string, partition int, start domain.ReplayFrom, handle func(domain.StreamMessage) error) error {
+	conn, err := s.dialer.DialLeader(ctx, "tcp", s.brokerAddress, topic, partition)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	first, end, err := conn.ReadOffsets()
+	if err != nil {
+		return err
+	}
+
+	offset := first
+	switch {
+	case start.Time != nil:
+		// Kafka answers -1 when nothing was written after the given time
+		if offset, err = conn.ReadOffset(*start.Time); err != nil {
+			return err
+		}
+		if offset < 0 {
+			return nil
+		}
+	case start.Offset != nil:
+		offset = max(*start.Offset, first)
+	}
+	if offset >= end {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   []string{s.brokerAddress},
+		Topic:     topic,
+		Partition: partition,
+		Dialer:    s.dialer,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(offset); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := handle(domain.StreamMessage{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Value:     msg.Value,
+		}); err != nil {
+			return err
+		}
+		if msg.Offset+1 >= end {
+			return nil
+		}
+	}
+}
@@ -3,32 +3,130 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
 	"github.com/segmentio/kafka-go"
 )
 
+// ProducerConfig configures topic routing and writer tuning
+type ProducerConfig struct {
+	BrokerAddress string
+	// DefaultTopic is used when neither the event type nor the caller pick a topic
+	DefaultTopic string
+	// EventTopics routes CloudEvents by type, overriding the topic passed to Publish.
+	// Types without a topic fall back to the topic passed to Publish, then DefaultTopic.
+	EventTopics map[string]string
+
+	BatchSize    int
+	BatchTimeout time.Duration
+	Compression  string // "", "none", "gzip", "snappy", "lz4" or "zstd"
+	RequiredAcks string // "none", "one" or "all"
+
+	Security SecurityConfig
+}
+
 type KafkaProducer struct {
-	writer *kafka.Writer
+	writer       *kafka.Writer
+	defaultTopic string
+	eventTopics  map[string]string
 }
 
-func NewKafkaProducer(brokerAddress, topic string) *KafkaProducer {
+func NewKafkaProducer(cfg ProducerConfig) (*KafkaProducer, error) {
+	transport, err := cfg.Security.transport()
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	acks, err := parseRequiredAcks(cfg.RequiredAcks)
+	if err != nil {
+		return nil, err
+	}
+
 	return &KafkaProducer{
+		// The topic is chosen per message, and messages with the same key share a partition
 		writer: &kafka.Writer{
-			Addr:     kafka.TCP(brokerAddress),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
+			Addr:         kafka.TCP(cfg.BrokerAddress),
+			Balancer:     &kafka.Hash{},
+			BatchSize:    cfg.BatchSize,
+			BatchTimeout: cfg.BatchTimeout,
+			Compression:  compression,
+			RequiredAcks: acks,
+			Transport:    transport,
 		},
-	}
+		defaultTopic: cfg.DefaultTopic,
+		eventTopics:  cfg.EventTopics,
+	}, nil
 }
 
+// Publish writes message to topic. CloudEvents are keyed by their subject (the book ID),
+// so every event for a book on one topic keeps its order on one partition, may be routed
+// to another topic by their type, and carry their type in the ce_type header.
 func (p *KafkaProducer) Publish(ctx context.Context, topic string, message interface{}) error {
 	msgBytes, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
+	var key []byte
+	var headers []kafka.Header
+	if event, ok := message.(*domain.CloudEvent); ok {
+		key = []byte(event.Subject)
+		headers = []kafka.Header{
+			{Key: "content-type", Value: []byte(domain.CloudEventsContentType)},
+			{Key: "ce_type", Value: []byte(event.Type)},
+		}
+		if routed, ok := p.eventTopics[event.Type]; ok && routed != "" {
+			topic = routed
+		}
+	}
+	if topic == "" {
+		topic = p.defaultTopic
+	}
+
 	return p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(topic),
-		Value: msgBytes,
+		Topic:   topic,
+		Key:     key,
+		Value:   msgBytes,
+		Headers: headers,
 	})
 }
+
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
+
+func parseCompression(name string) (kafka.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported compression codec %q", name)
+	}
+}
+
+func parseRequiredAcks(name string) (kafka.RequiredAcks, error) {
+	switch strings.ToLower(name) {
+	case "none":
+		return kafka.RequireNone, nil
+	case "", "one":
+		return kafka.RequireOne, nil
+	case "all":
+		return kafka.RequireAll, nil
+	default:
+		return 0, fmt.Errorf("unsupported required acks %q", name)
+	}
+}
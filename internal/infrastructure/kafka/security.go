@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SecurityConfig describes how to authenticate against the brokers
+type SecurityConfig struct {
+	TLSEnabled            bool
+	TLSInsecureSkipVerify bool
+	SASLMechanism         string // "", "plain", "scram-sha-256" or "scram-sha-512"
+	SASLUsername          string
+	SASLPassword          string
+}
+
+func (s SecurityConfig) tlsConfig() *tls.Config {
+	if !s.TLSEnabled {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: s.TLSInsecureSkipVerify,
+	}
+}
+
+func (s SecurityConfig) saslMechanism() (sasl.Mechanism, error) {
+	switch strings.ToLower(s.SASLMechanism) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: s.SASLUsername, Password: s.SASLPassword}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, s.SASLUsername, s.SASLPassword)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, s.SASLUsername, s.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", s.SASLMechanism)
+	}
+}
+
+// transport is used by writers
+func (s SecurityConfig) transport() (*kafka.Transport, error) {
+	mechanism, err := s.saslMechanism()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Transport{
+		TLS:  s.tlsConfig(),
+		SASL: mechanism,
+	}, nil
+}
+
+// dialer is used by readers
+func (s SecurityConfig) dialer() (*kafka.Dialer, error) {
+	mechanism, err := s.saslMechanism()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           s.tlsConfig(),
+		SASLMechanism: mechanism,
+	}, nil
+}
@@ -1,12 +1,35 @@
 package tables
 
-import "github.com/Redarcher9/Books-Management-System/internal/domain"
+import (
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
 
 type Books struct {
 	ID     int    `gorm:"column:id;primaryKey;autoIncrement"`
 	Title  string `gorm:"column:title"`
 	Author string `gorm:"column:author"`
 	Year   int    `gorm:"column:year"`
+
+	// Unique together, so that books differing only in case, spacing or Unicode form are duplicates
+	TitleNormalized  string `gorm:"column:title_normalized"`
+	AuthorNormalized string `gorm:"column:author_normalized"`
+
+	// Set on books merged into another book, which GORM then leaves out of every query
+	DeletedAt  gorm.DeletedAt `gorm:"column:deleted_at"`
+	MergedInto *int           `gorm:"column:merged_into"`
+}
+
+// NewBooks returns the row for book with its normalized columns set, leaving the ID to the database.
+// Fields that are empty in book stay empty, so the result can be used for partial updates.
+func NewBooks(book domain.Book) *Books {
+	return &Books{
+		Title:            book.Title,
+		Author:           book.Author,
+		Year:             book.Year,
+		TitleNormalized:  domain.NormalizeText(book.Title),
+		AuthorNormalized: domain.NormalizeText(book.Author),
+	}
 }
 
 func (b Books) TableName() string {
@@ -0,0 +1,42 @@
+package tables
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+type DeadLetterEvents struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	OutboxID       int64      `gorm:"column:outbox_id"`
+	AggregateID    int        `gorm:"column:aggregate_id"`
+	Topic          string     `gorm:"column:topic"`
+	Payload        string     `gorm:"column:payload;type:jsonb"`
+	Error          string     `gorm:"column:error"`
+	Attempts       int        `gorm:"column:attempts"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	DeadLetteredAt time.Time  `gorm:"column:dead_lettered_at;autoCreateTime"`
+	ReplayedAt     *time.Time `gorm:"column:replayed_at"`
+	DiscardedAt    *time.Time `gorm:"column:discarded_at"`
+}
+
+func (d DeadLetterEvents) TableName() string {
+	return "dead_letter_events"
+}
+
+func (d DeadLetterEvents) ToDomain() *domain.DeadLetter {
+	return &domain.DeadLetter{
+		ID:             d.ID,
+		OutboxID:       d.OutboxID,
+		AggregateID:    d.AggregateID,
+		Topic:          d.Topic,
+		Payload:        json.RawMessage(d.Payload),
+		Error:          d.Error,
+		Attempts:       d.Attempts,
+		CreatedAt:      d.CreatedAt,
+		DeadLetteredAt: d.DeadLetteredAt,
+		ReplayedAt:     d.ReplayedAt,
+		DiscardedAt:    d.DiscardedAt,
+	}
+}
@@ -0,0 +1,70 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+type BookEventStore struct {
+	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	BookID     int       `gorm:"column:book_id"`
+	Version    int       `gorm:"column:version"`
+	EventID    string    `gorm:"column:event_id"`
+	EventType  string    `gorm:"column:event_type"`
+	Payload    string    `gorm:"column:payload;type:jsonb"`
+	OccurredAt time.Time `gorm:"column:occurred_at"`
+}
+
+func (e BookEventStore) TableName() string {
+	return "book_event_store"
+}
+
+type BookSnapshots struct {
+	BookID     int       `gorm:"column:book_id;primaryKey"`
+	Version    int       `gorm:"column:version;primaryKey"`
+	State      string    `gorm:"column:state;type:jsonb"`
+	Deleted    bool      `gorm:"column:deleted"`
+	OccurredAt time.Time `gorm:"column:occurred_at"`
+}
+
+func (s BookSnapshots) TableName() string {
+	return "book_snapshots"
+}
+
+// BookEventState is the current state of a live event-sourced book
+type BookEventState struct {
+	BookID           int    `gorm:"column:book_id;primaryKey"`
+	Version          int    `gorm:"column:version"`
+	Title            string `gorm:"column:title"`
+	Author           string `gorm:"column:author"`
+	Year             int    `gorm:"column:year"`
+	TitleNormalized  string `gorm:"column:title_normalized"`
+	AuthorNormalized string `gorm:"column:author_normalized"`
+}
+
+// NewBookEventState returns the state row of book at version, with its normalized columns set
+func NewBookEventState(book domain.Book, version int) *BookEventState {
+	return &BookEventState{
+		BookID:           book.ID,
+		Version:          version,
+		Title:            book.Title,
+		Author:           book.Author,
+		Year:             book.Year,
+		TitleNormalized:  domain.NormalizeText(book.Title),
+		AuthorNormalized: domain.NormalizeText(book.Author),
+	}
+}
+
+func (s BookEventState) TableName() string {
+	return "book_event_state"
+}
+
+func (s BookEventState) ToDomain() *domain.Book {
+	return &domain.Book{
+		ID:     s.BookID,
+		Title:  s.Title,
+		Author: s.Author,
+		Year:   s.Year,
+	}
+}
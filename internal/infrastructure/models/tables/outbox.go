@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+type BookOutbox struct {
+	ID            int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	AggregateID   int        `gorm:"column:aggregate_id"`
+	Topic         string     `gorm:"column:topic"`
+	Payload       string     `gorm:"column:payload;type:jsonb"`
+	Attempts      int        `gorm:"column:attempts"`
+	LastError     *string    `gorm:"column:last_error"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+	PublishedAt   *time.Time `gorm:"column:published_at"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at;autoCreateTime"`
+}
+
+func (o BookOutbox) TableName() string {
+	return "book_outbox"
+}
+
+func (o BookOutbox) ToDomain() *domain.OutboxMessage {
+	return &domain.OutboxMessage{
+		ID:          o.ID,
+		AggregateID: o.AggregateID,
+		Topic:       o.Topic,
+		Payload:     json.RawMessage(o.Payload),
+		Attempts:    o.Attempts,
+		CreatedAt:   o.CreatedAt,
+	}
+}
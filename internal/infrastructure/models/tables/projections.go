@@ -0,0 +1,30 @@
+package tables
+
+import "time"
+
+type ProjectionCheckpoints struct {
+	Projection string    `gorm:"column:projection;primaryKey"`
+	Topic      string    `gorm:"column:topic;primaryKey"`
+	Partition  int       `gorm:"column:partition;primaryKey"`
+	NextOffset int64     `gorm:"column:next_offset"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (p ProjectionCheckpoints) TableName() string {
+	return "projection_checkpoints"
+}
+
+type BookSearch struct {
+	BookID        int       `gorm:"column:book_id;primaryKey"`
+	Title         string    `gorm:"column:title"`
+	Author        string    `gorm:"column:author"`
+	Year          int       `gorm:"column:year"`
+	SearchText    string    `gorm:"column:search_text"`
+	Deleted       bool      `gorm:"column:deleted"`
+	LastEventID   string    `gorm:"column:last_event_id"`
+	LastEventTime time.Time `gorm:"column:last_event_time"`
+}
+
+func (b BookSearch) TableName() string {
+	return "book_search"
+}
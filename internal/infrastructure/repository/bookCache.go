@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCacheTTL      = 10 * time.Minute
+	defaultLocalCacheTTL = 30 * time.Second
+)
+
+// CacheConfig sets how long cached books are fresh (TTL) and for how much longer
+// they may still be served while a refresh runs (StaleTTL). Up to LocalSize entries are
+// also kept in process for LocalTTL; a LocalSize of 0 disables the in-process tier.
+// Lookups that found nothing are remembered for NegativeTTL; 0 disables negative caching.
+type CacheConfig struct {
+	TTL         time.Duration
+	StaleTTL    time.Duration
+	LocalSize   int
+	LocalTTL    time.Duration
+	NegativeTTL time.Duration
+}
+
+// cacheEntry is what is stored in the cache; FreshUntil is the soft TTL.
+// NotFound entries record that there was nothing to load.
+type cacheEntry struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	NotFound   bool            `json:"not_found,omitempty"`
+	FreshUntil time.Time       `json:"fresh_until"`
+}
+
+// cached returns the value under key from the in-process cache or the shared cache, using
+// load to fill both on a miss. Concurrent misses for the same key share a single load. Entries past
+// their soft TTL are returned immediately while one background load refreshes them.
+// The outcome is recorded with domain.RecordCacheStatus.
+func cached[T any](ctx context.Context, b *Books, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if !b.sharedCacheCurrent(ctx) {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return load(ctx)
+	}
+	if entry, ok := b.getCacheEntry(ctx, key); ok {
+		stale := time.Now().After(entry.FreshUntil)
+		if entry.NotFound && !stale {
+			domain.RecordCacheStatus(ctx, domain.CacheHit)
+			return zero, gorm.ErrRecordNotFound
+		}
+
+		var value T
+		if !entry.NotFound && json.Unmarshal(entry.Data, &value) == nil {
+			if stale {
+				domain.RecordCacheStatus(ctx, domain.CacheStale)
+				b.group.DoChan(key, loadAndStore(ctx, b, key, load))
+			} else {
+				domain.RecordCacheStatus(ctx, domain.CacheHit)
+			}
+			return value, nil
+		}
+	}
+
+	domain.RecordCacheStatus(ctx, domain.CacheMiss)
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-b.group.DoChan(key, loadAndStore(ctx, b, key, load)):
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
+
+// loadAndStore wraps load for singleflight, caching successful results and, for NegativeTTL, missing ones.
+// The load is detached from the cancellation of ctx, because other callers may be waiting for it.
+// A result loaded while a write invalidated the cache is not kept, since it may predate the write.
+func loadAndStore[T any](ctx context.Context, b *Books, key string, load func(ctx context.Context) (T, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+
+		// Every write moves the list version before it deletes the keys it changed
+		version, versioned := b.sharedListCacheVersion(ctx)
+		value, err := load(ctx)
+		if errors.Is(err, gorm.ErrRecordNotFound) && b.cacheConfig.NegativeTTL > 0 && versioned {
+			b.storeNotFound(ctx, key)
+			b.dropIfInvalidated(ctx, key, version)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if versioned {
+			b.storeValue(ctx, key, value)
+			b.dropIfInvalidated(ctx, key, version)
+		}
+		return value, nil
+	}
+}
+
+// dropIfInvalidated deletes the entry just stored under key when a write moved the list version away from
+// version since its load started. A write moving it after this check deletes the entry itself.
+func (b *Books) dropIfInvalidated(ctx context.Context, key string, version int64) {
+	if current, ok := b.sharedListCacheVersion(ctx); ok && current == version {
+		return
+	}
+	b.cache.Del(ctx, key)
+	b.local.Del(ctx, key)
+}
+
+// detach returns a context that is not cancelled with ctx but still ends at its deadline
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+// storeValue caches value in the shared cache and in process
+func (b *Books) storeValue(ctx context.Context, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	b.storeEntry(ctx, key, cacheEntry{
+		Data:       data,
+		FreshUntil: time.Now().Add(b.cacheConfig.TTL),
+	}, b.cacheConfig.TTL+b.cacheConfig.StaleTTL)
+}
+
+func (b *Books) storeNotFound(ctx context.Context, key string) {
+	b.storeEntry(ctx, key, cacheEntry{
+		NotFound:   true,
+		FreshUntil: time.Now().Add(b.cacheConfig.NegativeTTL),
+	}, b.cacheConfig.NegativeTTL)
+}
+
+func (b *Books) storeEntry(ctx context.Context, key string, entry cacheEntry, ttl time.Duration) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	b.cache.Set(ctx, key, data, ttl)
+	b.local.Set(ctx, key, data, min(b.cacheConfig.LocalTTL, ttl))
+}
+
+// getCacheEntry looks key up in process first and then in the shared cache, keeping shared hits locally
+func (b *Books) getCacheEntry(ctx context.Context, key string) (*cacheEntry, bool) {
+	cachedData, err := b.local.Get(ctx, key)
+	if err != nil {
+		if cachedData, err = b.cache.Get(ctx, key); err != nil {
+			return nil, false
+		}
+		b.local.Set(ctx, key, cachedData, b.cacheConfig.LocalTTL)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(cachedData, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
@@ -0,0 +1,179 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache/cachetest"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+// Books must keep working, uncached, while Redis is down
+func TestSQLiteBooksCacheDown(t *testing.T) {
+	servicetest.TestBookRepo(t, func(t *testing.T) service.BookRepo {
+		bookCache := cachetest.New()
+		bookCache.FailWith(errors.New("redis: connection refused"))
+		return repository.NewBooksRepo(openSQLite(t), bookCache, repository.CacheConfig{LocalSize: 100})
+	})
+}
+
+func TestBooksCacheStatus(t *testing.T) {
+	bookCache := cachetest.New()
+	books := repository.NewBooksRepo(openSQLite(t), bookCache, repository.CacheConfig{})
+	book := servicetest.CreateBook(t, books, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+	tests := []struct {
+		name      string
+		cacheDown bool
+		want      domain.CacheStatus
+	}{
+		{name: "first read", want: domain.CacheMiss},
+		{name: "second read", want: domain.CacheHit},
+		{name: "cache down", cacheDown: true, want: domain.CacheMiss},
+		{name: "cache back", want: domain.CacheHit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cacheDown {
+				bookCache.FailWith(errors.New("redis: connection refused"))
+				defer bookCache.FailWith(nil)
+			}
+			ctx, recorder := domain.WithCacheStatusRecorder(context.Background())
+			if _, err := books.GetBookByID(ctx, book.ID); err != nil {
+				t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+			}
+			if got := recorder.Status(); got != tt.want {
+				t.Errorf("cache status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// A write on one instance must reach the in-process caches of the other instances
+func TestBooksInvalidatesOtherInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "books.db")
+	bookCache := cachetest.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	instances := make([]*repository.Books, 2)
+	for i := range instances {
+		gormDB, err := repository.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("OpenSQLite: %v", err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := gormDB.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		instances[i] = repository.NewBooksRepo(gormDB, bookCache, repository.CacheConfig{LocalSize: 100, LocalTTL: time.Hour})
+		go instances[i].ListenForInvalidations(ctx)
+	}
+	reader, writer := instances[0], instances[1]
+	for deadline := time.Now().Add(2 * time.Second); bookCache.Subscribers() < len(instances); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("instances did not subscribe to invalidations")
+		}
+	}
+
+	book := servicetest.CreateBook(t, writer, "The Hobit", "J.R.R. Tolkien", 1937)
+	if _, err := reader.GetBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+	if err := writer.UpdateBookByID(ctx, book.ID, domain.Book{Title: "The Hobbit"}); err != nil {
+		t.Fatalf("UpdateBookByID(%d): %v", book.ID, err)
+	}
+
+	got, err := reader.GetBookByID(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+	if got.Title != "The Hobbit" {
+		t.Errorf("reader returns %+v after the update on another instance", *got)
+	}
+}
+
+// An invalidation missed while the cache was down must be applied before the cache is read again
+func TestBooksRetriesMissedInvalidations(t *testing.T) {
+	ctx := context.Background()
+	bookCache := cachetest.New()
+	books := repository.NewBooksRepo(openSQLite(t), bookCache, repository.CacheConfig{})
+	book := servicetest.CreateBook(t, books, "The Hobit", "J.R.R. Tolkien", 1937)
+	if _, err := books.GetBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+	if _, err := books.GetBooks(ctx, domain.BookQuery{Limit: 10}.Normalize()); err != nil {
+		t.Fatalf("GetBooks: %v", err)
+	}
+
+	bookCache.FailWith(errors.New("cache circuit open"))
+	if err := books.UpdateBookByID(ctx, book.ID, domain.Book{Title: "The Hobbit"}); err != nil {
+		t.Fatalf("UpdateBookByID(%d): %v", book.ID, err)
+	}
+	bookCache.FailWith(nil)
+
+	got, err := books.GetBookByID(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+	if got.Title != "The Hobbit" {
+		t.Errorf("GetBookByID returns %+v after the cache came back", *got)
+	}
+	list, err := books.GetBooks(ctx, domain.BookQuery{Limit: 10}.Normalize())
+	if err != nil {
+		t.Fatalf("GetBooks: %v", err)
+	}
+	if len(list.Books) != 1 || list.Books[0].Title != "The Hobbit" {
+		t.Errorf("GetBooks returns %v after the cache came back", list.Books)
+	}
+}
+
+// racingCache runs beforeSet once, just before the first value is stored under key
+type racingCache struct {
+	*cachetest.Cache
+	key       string
+	beforeSet func()
+}
+
+func (c *racingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if key == c.key && c.beforeSet != nil {
+		beforeSet := c.beforeSet
+		c.beforeSet = nil
+		beforeSet()
+	}
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+// A book loaded before a write and stored after its invalidation must not outlive the invalidation
+func TestBooksDropsLoadsRacingAWrite(t *testing.T) {
+	ctx := context.Background()
+	bookCache := &racingCache{Cache: cachetest.New()}
+	books := repository.NewBooksRepo(openSQLite(t), bookCache, repository.CacheConfig{})
+	book := servicetest.CreateBook(t, books, "The Hobit", "J.R.R. Tolkien", 1937)
+
+	bookCache.key = fmt.Sprintf("books:%d", book.ID)
+	bookCache.beforeSet = func() {
+		if err := books.UpdateBookByID(ctx, book.ID, domain.Book{Title: "The Hobbit"}); err != nil {
+			t.Errorf("UpdateBookByID(%d): %v", book.ID, err)
+		}
+	}
+	if _, err := books.GetBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+
+	got, err := books.GetBookByID(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("GetBookByID(%d): %v", book.ID, err)
+	}
+	if got.Title != "The Hobbit" {
+		t.Errorf("GetBookByID returns %+v after the racing update", *got)
+	}
+}
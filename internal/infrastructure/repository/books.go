@@ -2,89 +2,216 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
 	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
-	"github.com/go-redis/redis"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Books struct {
-	gormDB  *gorm.DB
-	redisDB *redis.Client
+	gormDB      *gorm.DB
+	cache       Cache
+	cacheConfig CacheConfig
+	group       singleflight.Group
+	local       localCache
+	instanceID  string
+
+	// Invalidations the shared cache missed, while it was unreachable or its circuit was open
+	staleMu       sync.Mutex
+	stale         bool
+	staleKeys     map[string]bool
+	staleRetrying bool // An invalidation is being retried
+	staleMissed   bool // Another invalidation was missed during the retry
 }
 
 const (
+	bookCacheKeyPattern = "books:*" // Matches every key of the book cache keyspace
 	bookListCacheKey    = "books:all"
+	bookListVersionKey  = "books:all:version" // Namespace version embedded in every list cache key
 	bookByIDCacheFormat = "books:%d"
-	cacheTTL            = 10 * time.Minute // Cache expiration time
 )
 
-func NewBooksRepo(gormDB *gorm.DB, redisDB *redis.Client) *Books {
+// similarTitleThreshold is the default pg_trgm.similarity_threshold used by the % operator
+const similarTitleThreshold = 0.3
+
+func NewBooksRepo(gormDB *gorm.DB, bookCache Cache, cacheConfig CacheConfig) *Books {
+	if cacheConfig.TTL <= 0 {
+		cacheConfig.TTL = defaultCacheTTL
+	}
+	if cacheConfig.LocalTTL <= 0 {
+		cacheConfig.LocalTTL = defaultLocalCacheTTL
+	}
+	if bookCache == nil {
+		bookCache = cache.NoOp{}
+	}
+
+	var local localCache = cache.NoOp{}
+	if cacheConfig.LocalSize > 0 {
+		local = cache.NewMemory(cacheConfig.LocalSize)
+	}
 	return &Books{
-		gormDB:  gormDB,
-		redisDB: redisDB,
+		gormDB:      gormDB,
+		cache:       bookCache,
+		cacheConfig: cacheConfig,
+		local:       local,
+		instanceID:  newInstanceID(),
 	}
 }
 
-func (b *Books) GetBooks(ctx context.Context, offset, limit int) ([]*domain.Book, error) {
-	cacheKey := fmt.Sprintf("%s:%d:%d", bookListCacheKey, offset, limit)
+// GetBooks returns the page of books selected by query, filtered, sorted and paged by the database
+func (b *Books) GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookList, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		// Without the current version a stale page could be read, so skip the cache
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.loadBooks(ctx, query)
+	}
+	return cached(ctx, b, listCacheKey(version, query), func(ctx context.Context) (*domain.BookList, error) {
+		return b.loadBooks(ctx, query)
+	})
+}
 
-	// Check if data is available in Redis
-	if cachedData, err := b.redisDB.Get(cacheKey).Result(); err == nil {
-		var domainBooks []*domain.Book
-		if err := json.Unmarshal([]byte(cachedData), &domainBooks); err == nil {
-			fmt.Println("Fetched from cache")
-			return domainBooks, nil
-		}
+func (b *Books) loadBooks(ctx context.Context, query domain.BookQuery) (*domain.BookList, error) {
+	db := filterBooks(b.gormDB.WithContext(ctx).Model(&tables.Books{}), query)
+	before := query.Cursor != nil && query.Cursor.Before
+	if query.Cursor != nil {
+		db = db.Where(keyset(db, query.Sort, *query.Cursor))
+	}
+	for _, sort := range query.Sort {
+		// A page before the cursor is read backwards, starting from the cursor
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sortColumn(db, sort.Field), Raw: true}, Desc: sort.Descending != before})
 	}
 
+	// One more book than asked for tells whether another page follows
 	var books []*tables.Books
-	result := b.gormDB.Model(&tables.Books{}).
-		Limit(limit).
-		Offset(offset).
-		Find(&books)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// if book not found return nil
-			return nil, nil
-		}
-		return nil, result.Error
-	}
-	if len(books) == 0 {
-		return nil, gorm.ErrRecordNotFound
+	if err := db.Limit(query.Limit + 1).Find(&books).Error; err != nil {
+		return nil, err
 	}
 
 	domainBooks := make([]*domain.Book, 0, len(books))
 	for _, b := range books {
 		domainBooks = append(domainBooks, b.ToDomain())
 	}
+	list := &domain.BookList{}
+	list.Books, list.More = pageOf(domainBooks, query.Limit, before)
+	return list, nil
+}
 
-	// Cache the result in Redis
-	data, _ := json.Marshal(domainBooks)
-	b.redisDB.Set(cacheKey, data, cacheTTL)
+// CountBooks counts the books matching the filters of query
+func (b *Books) CountBooks(ctx context.Context, query domain.BookQuery) (int64, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.countBooks(ctx, query)
+	}
+	return cached(ctx, b, countCacheKey(version, query), func(ctx context.Context) (int64, error) {
+		return b.countBooks(ctx, query)
+	})
+}
 
-	return domainBooks, nil
+func (b *Books) countBooks(ctx context.Context, query domain.BookQuery) (int64, error) {
+	var count int64
+	err := filterBooks(b.gormDB.WithContext(ctx).Model(&tables.Books{}), query).Count(&count).Error
+	return count, err
 }
 
-func (b *Books) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
-	cacheKey := fmt.Sprintf(bookByIDCacheFormat, ID)
+// pageOf trims items read in the direction of travel, which hold one item more than limit when
+// another page follows, and returns them in the order of the query with whether more follow
+func pageOf[T any](items []T, limit int, before bool) ([]T, bool) {
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if before {
+		slices.Reverse(items)
+	}
+	return items, more
+}
 
-	// Check if data is available in Redis
-	if cachedData, err := b.redisDB.Get(cacheKey).Result(); err == nil {
-		var book domain.Book
-		if err := json.Unmarshal([]byte(cachedData), &book); err == nil {
-			fmt.Println("Fetched from cache")
-			return &book, nil
+// keyset returns the condition selecting the books sorted after the cursor, or before it, like
+// (year < ?) OR (year = ? AND id > ?) for the sort -year,id
+func keyset(db *gorm.DB, sorts []domain.BookSort, cursor domain.BookCursor) clause.Expr {
+	conditions := make([]string, 0, len(sorts))
+	var vars []interface{}
+	for i, sort := range sorts {
+		parts := make([]string, 0, i+1)
+		for _, equal := range sorts[:i] {
+			parts = append(parts, sortColumn(db, equal.Field)+" = ?")
+			vars = append(vars, equal.Value(cursor.Book))
 		}
+		operator := ">"
+		if sort.Descending != cursor.Before {
+			operator = "<"
+		}
+		parts = append(parts, sortColumn(db, sort.Field)+" "+operator+" ?")
+		vars = append(vars, sort.Value(cursor.Book))
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
+
+// bookSortColumns are the columns each sort field orders by. Titles and authors are sorted normalized, ignoring case.
+var bookSortColumns = map[string]string{
+	domain.BookSortID:     "id",
+	domain.BookSortTitle:  "title_normalized",
+	domain.BookSortAuthor: "author_normalized",
+	domain.BookSortYear:   "year",
+}
+
+// sortColumn returns the column db orders the sort field by. Postgres compares text by the collation of
+// the database, so titles and authors are compared byte by byte instead, like SQLite and BookSort.Compare.
+func sortColumn(db *gorm.DB, field string) string {
+	column := bookSortColumns[field]
+	if isPostgres(db) && (field == domain.BookSortTitle || field == domain.BookSortAuthor) {
+		return column + ` COLLATE "C"`
+	}
+	return column
+}
+
+// filterBooks adds the filters of the normalized query to db
+func filterBooks(db *gorm.DB, query domain.BookQuery) *gorm.DB {
+	if query.Author != "" {
+		db = db.Where("author_normalized = ?", query.Author)
+	}
+	if query.Title != "" {
+		db = db.Where(`title_normalized LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(query.Title)+"%")
+	}
+	if query.YearFrom != 0 {
+		db = db.Where("year >= ?", query.YearFrom)
+	}
+	if query.YearTo != 0 {
+		db = db.Where("year <= ?", query.YearTo)
+	}
+	if len(query.IDs) > 0 {
+		db = db.Where("id IN ?", query.IDs)
 	}
+	return db
+}
+
+// likeEscaper escapes the LIKE wildcards in a substring searched for
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (b *Books) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
+	return cached(ctx, b, bookCacheKey(ID), func(ctx context.Context) (*domain.Book, error) {
+		return b.loadBookByID(ctx, ID)
+	})
+}
 
+func (b *Books) loadBookByID(ctx context.Context, ID int) (*domain.Book, error) {
 	var book tables.Books // Note: Not a pointer here
-	result := b.gormDB.
+	result := b.gormDB.WithContext(ctx).
 		Where("id = ?", ID).
 		First(&book)
 
@@ -94,64 +221,385 @@ func (b *Books) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
 		}
 		return nil, fmt.Errorf("failed to get book by ID: %w", result.Error)
 	}
+	return book.ToDomain(), nil
+}
 
-	// Cache the result in Redis
-	data, _ := json.Marshal(book.ToDomain())
-	b.redisDB.Set(cacheKey, data, cacheTTL)
+// FindSimilarBooks returns up to limit books whose title is similar to title according to pg_trgm, most similar first.
+// Without pg_trgm, as on SQLite, every title is compared in process instead.
+func (b *Books) FindSimilarBooks(ctx context.Context, title string, limit int) ([]*domain.Book, error) {
+	if !isPostgres(b.gormDB) {
+		books, err := b.allBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return similarBooks(books, title, limit), nil
+	}
 
-	return book.ToDomain(), nil
+	normalized := domain.NormalizeText(title)
+	var books []*tables.Books
+	err := b.gormDB.WithContext(ctx).
+		Where("title_normalized % ?", normalized).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "similarity(title_normalized, ?) DESC",
+			Vars:               []interface{}{normalized},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	domainBooks := make([]*domain.Book, 0, len(books))
+	for _, b := range books {
+		domainBooks = append(domainBooks, b.ToDomain())
+	}
+	return domainBooks, nil
 }
 
-func (b *Books) CreateBook(ctx context.Context, book *domain.Book) error {
-	// Check if the book already exists (by Title and Author)
-	var existingBook tables.Books
-	if err := b.gormDB.Where("title = ? AND author = ?", book.Title, book.Author).First(&existingBook).Error; err == nil {
-		return gorm.ErrDuplicatedKey
+// allBooks loads every book, for the searches that SQLite can not run itself
+func (b *Books) allBooks(ctx context.Context) ([]*domain.Book, error) {
+	var books []*tables.Books
+	if err := b.gormDB.WithContext(ctx).Order("id").Find(&books).Error; err != nil {
+		return nil, err
+	}
+	domainBooks := make([]*domain.Book, 0, len(books))
+	for _, b := range books {
+		domainBooks = append(domainBooks, b.ToDomain())
+	}
+	return domainBooks, nil
+}
+
+// similarBooks returns up to limit of books whose title is at least as similar to title
+// as the pg_trgm similarity threshold requires, most similar first
+func similarBooks(books []*domain.Book, title string, limit int) []*domain.Book {
+	similarity := make(map[int]float64, len(books))
+	var similar []*domain.Book
+	for _, book := range books {
+		if score := domain.TitleSimilarity(title, book.Title); score >= similarTitleThreshold {
+			similarity[book.ID] = score
+			similar = append(similar, book)
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similarity[similar[i].ID] > similarity[similar[j].ID]
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
 	}
+	return similar
+}
 
-	newBook := &tables.Books{
-		Title:  book.Title,
-		Author: book.Author,
-		Year:   book.Year,
+// queryBooks filters, sorts and pages books in process the way loadBooks does in the database
+func queryBooks(books []*domain.Book, query domain.BookQuery) *domain.BookList {
+	before := query.Cursor != nil && query.Cursor.Before
+	var selected []*domain.Book
+	for _, book := range books {
+		if !query.Matches(*book) {
+			continue
+		}
+		if query.Cursor != nil {
+			if c := query.Compare(*book, query.Cursor.Book); (before && c >= 0) || (!before && c <= 0) {
+				continue
+			}
+		}
+		selected = append(selected, book)
+	}
+
+	// Sorted in the direction of travel, starting from the cursor
+	slices.SortStableFunc(selected, func(a, b *domain.Book) int {
+		if before {
+			return query.Compare(*b, *a)
+		}
+		return query.Compare(*a, *b)
+	})
+	if len(selected) > query.Limit+1 {
+		selected = selected[:query.Limit+1]
 	}
-	if err := b.gormDB.Create(newBook).Error; err != nil {
+	list := &domain.BookList{}
+	list.Books, list.More = pageOf(selected, query.Limit, before)
+	return list
+}
+
+// countMatching counts the books matching the filters of query
+func countMatching(books []*domain.Book, query domain.BookQuery) int64 {
+	var count int64
+	for _, book := range books {
+		if query.Matches(*book) {
+			count++
+		}
+	}
+	return count
+}
+
+func (b *Books) CreateBook(ctx context.Context, book *domain.Book) error {
+	newBook := tables.NewBooks(*book)
+	// Write the book and its outbox event atomically. Duplicates (by normalized Title and Author)
+	// are rejected by a unique index, which the database reports as gorm.ErrDuplicatedKey.
+	err := b.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newBook).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, domain.BookCreated{After: *newBook.ToDomain()})
+	})
+	if err != nil {
 		return err
 	}
+	book.ID = newBook.ID
 
-	b.expireCache()
+	// The new ID may have been cached as missing
+	b.invalidate(ctx, book.ID)
 	return nil
 }
 
 func (b *Books) UpdateBookByID(ctx context.Context, ID int, book domain.Book) error {
-	response := b.gormDB.Model(&tables.Books{}).Where("id = ?", ID).Updates(book)
-	if response.Error != nil {
-		return response.Error
-	}
+	err := b.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before tables.Books
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ID).First(&before).Error; err != nil {
+			return err
+		}
 
-	if response.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		response := tx.Model(&tables.Books{}).Where("id = ?", ID).Updates(tables.NewBooks(book))
+		if response.Error != nil {
+			return response.Error
+		}
+
+		var after tables.Books
+		if err := tx.Where("id = ?", ID).First(&after).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, domain.BookUpdated{Before: *before.ToDomain(), After: *after.ToDomain()})
+	})
+	if err != nil {
+		return err
 	}
 
-	b.expireCache()
-	b.redisDB.Del(fmt.Sprintf(bookByIDCacheFormat, ID))
+	b.invalidate(ctx, ID)
 	return nil
 }
 
 func (b *Books) DeleteBookByID(ctx context.Context, ID int) error {
-	response := b.gormDB.Where("id = ?", ID).Delete(&tables.Books{})
-	if response.Error != nil {
-		return response.Error
+	err := b.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before tables.Books
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ID).First(&before).Error; err != nil {
+			// Nothing to delete, so there is nothing to announce
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Only merged books are soft deleted
+		if err := tx.Unscoped().Delete(&before).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, domain.BookDeleted{Before: *before.ToDomain()})
+	})
+	if err != nil {
+		return err
 	}
-	b.expireCache()
-	b.redisDB.Del(fmt.Sprintf(bookByIDCacheFormat, ID))
+	b.invalidate(ctx, ID)
 	return nil
 }
 
-// expireCache clears relevant cache entries in Redis
-func (b *Books) expireCache() {
-	// Delete all book list caches
-	keys, err := b.redisDB.Keys("books:all:*").Result()
-	if err == nil && len(keys) > 0 {
-		b.redisDB.Del(keys...)
+// MergeBooks merges the source book into the target book. The source book is soft deleted and
+// points at the target, as do the books that were merged into it before.
+func (b *Books) MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error) {
+	var merged domain.Book
+	err := b.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock both books in ID order, so concurrent merges of the same pair can not deadlock
+		var books []*tables.Books
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []int{targetID, request.SourceID}).
+			Order("id").
+			Find(&books).Error; err != nil {
+			return err
+		}
+		var target, source *domain.Book
+		for _, book := range books {
+			switch book.ID {
+			case targetID:
+				target = book.ToDomain()
+			case request.SourceID:
+				source = book.ToDomain()
+			}
+		}
+		if target == nil || source == nil {
+			return gorm.ErrRecordNotFound
+		}
+		merged = request.Merge(*target, *source)
+
+		// Remove the source first, so the target may take over its title and author
+		if err := tx.Model(&tables.Books{}).Where("id = ?", source.ID).Updates(map[string]interface{}{
+			"deleted_at":  time.Now(),
+			"merged_into": target.ID,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&tables.Books{}).Where("merged_into = ?", source.ID).Update("merged_into", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&tables.Books{}).Where("id = ?", target.ID).
+			Select("title", "author", "year", "title_normalized", "author_normalized").
+			Updates(tables.NewBooks(merged)).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, domain.BookMerged{Before: *target, After: merged, Source: *source})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.invalidate(ctx, targetID, request.SourceID)
+	return &merged, nil
+}
+
+// invalidate drops the given books and every cached book list from the cache and from the
+// in-process cache of this and all other instances
+func (b *Books) invalidate(ctx context.Context, IDs ...int) {
+	// The write is committed, so the cache must follow even if the caller has gone away
+	ctx = context.WithoutCancel(ctx)
+
+	keys := []string{bookListVersionKey}
+	for _, ID := range IDs {
+		keys = append(keys, bookCacheKey(ID))
+	}
+	if err := b.expireCache(ctx, keys[1:]); err != nil {
+		b.markStale(keys[1:])
 	}
+	b.local.Del(ctx, keys...)
+	b.publishInvalidation(ctx, keys)
+}
+
+// expireCache invalidates every cached book list in O(1) by moving to a new namespace version, and deletes
+// the given book keys. Lists cached under older versions are never read again and expire on their own.
+func (b *Books) expireCache(ctx context.Context, bookKeys []string) error {
+	b.seedListCacheVersion(ctx)
+	if _, err := b.cache.Incr(ctx, bookListVersionKey); err != nil {
+		return err
+	}
+	if len(bookKeys) == 0 {
+		return nil
+	}
+	return b.cache.Del(ctx, bookKeys...)
+}
+
+// markStale remembers that the shared cache missed an invalidation of the lists and of bookKeys
+func (b *Books) markStale(bookKeys []string) {
+	b.staleMu.Lock()
+	defer b.staleMu.Unlock()
+	b.stale = true
+	b.staleMissed = b.staleMissed || b.staleRetrying
+	if b.staleKeys == nil {
+		b.staleKeys = make(map[string]bool)
+	}
+	for _, key := range bookKeys {
+		b.staleKeys[key] = true
+	}
+}
+
+// sharedCacheCurrent reports whether the shared cache may be used. After an invalidation was missed,
+// for example while the cache circuit was open, it is not used until the missed invalidations succeed,
+// so entries written before the write are not served once the cache recovers.
+func (b *Books) sharedCacheCurrent(ctx context.Context) bool {
+	b.staleMu.Lock()
+	if !b.stale || b.staleRetrying {
+		defer b.staleMu.Unlock()
+		return !b.stale
+	}
+	bookKeys := make([]string, 0, len(b.staleKeys))
+	for key := range b.staleKeys {
+		bookKeys = append(bookKeys, key)
+	}
+	b.staleRetrying = true
+	b.staleMu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	err := b.expireCache(ctx, bookKeys)
+	if err == nil {
+		b.publishInvalidation(ctx, append([]string{bookListVersionKey}, bookKeys...))
+	}
+
+	b.staleMu.Lock()
+	defer b.staleMu.Unlock()
+	b.staleRetrying = false
+	if err != nil {
+		return false
+	}
+	// An invalidation missed during the retry may have come after it, so everything is retried again
+	if b.staleMissed {
+		b.staleMissed = false
+		return false
+	}
+	b.stale, b.staleKeys = false, nil
+	return true
+}
+
+// listCacheVersion returns the current list namespace version, or false when it can not be read.
+// If the version key is missing (never set, or evicted) it is seeded with the current time,
+// so that lists cached under an earlier version can not be picked up again.
+func (b *Books) listCacheVersion(ctx context.Context) (int64, bool) {
+	if !b.sharedCacheCurrent(ctx) {
+		return 0, false
+	}
+	if cachedVersion, err := b.local.Get(ctx, bookListVersionKey); err == nil {
+		if version, err := strconv.ParseInt(string(cachedVersion), 10, 64); err == nil {
+			return version, true
+		}
+	}
+
+	version, ok := b.sharedListCacheVersion(ctx)
+	if ok {
+		b.local.Set(ctx, bookListVersionKey, []byte(strconv.FormatInt(version, 10)), b.cacheConfig.LocalTTL)
+	}
+	return version, ok
+}
+
+// sharedListCacheVersion reads the list namespace version from the shared cache, skipping the in-process copy
+func (b *Books) sharedListCacheVersion(ctx context.Context) (int64, bool) {
+	cachedVersion, err := b.cache.Get(ctx, bookListVersionKey)
+	if errors.Is(err, cache.ErrMiss) {
+		b.seedListCacheVersion(ctx)
+		cachedVersion, err = b.cache.Get(ctx, bookListVersionKey)
+	}
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(string(cachedVersion), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+func (b *Books) seedListCacheVersion(ctx context.Context) {
+	b.cache.SetNX(ctx, bookListVersionKey, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)), 0)
+}
+
+func bookCacheKey(ID int) string {
+	return fmt.Sprintf(bookByIDCacheFormat, ID)
+}
+
+// listCacheKey returns the key of a page of the book list. The normalized filters and sort are part of the key,
+// so equal queries share an entry however their parameters were written.
+func listCacheKey(version int64, query domain.BookQuery) string {
+	key := fmt.Sprintf("%s:v%d:%d:%s", bookListCacheKey, version, query.Limit, query.FilterKey())
+	if query.Cursor == nil {
+		return key
+	}
+
+	direction := "after"
+	if query.Cursor.Before {
+		direction = "before"
+	}
+	values := make([]string, 0, len(query.Sort))
+	for _, sort := range query.Sort {
+		values = append(values, fmt.Sprint(sort.Value(query.Cursor.Book)))
+	}
+	return key + ":" + url.Values{direction: values}.Encode()
+}
+
+// countCacheKey returns the key of the number of books matching the filters of query
+func countCacheKey(version int64, query domain.BookQuery) string {
+	return fmt.Sprintf("%s:v%d:count:%s", bookListCacheKey, version, query.FilterKey())
 }
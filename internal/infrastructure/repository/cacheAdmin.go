@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
+)
+
+// CacheStats counts the keys of the book cache keyspace in the shared cache
+func (b *Books) CacheStats(ctx context.Context) (*domain.CacheStats, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		return nil, domain.ErrCacheUnavailable
+	}
+	keys, err := b.cache.Keys(ctx, bookCacheKeyPattern)
+	if err != nil {
+		return nil, cacheUnavailable(err)
+	}
+
+	stats := &domain.CacheStats{
+		ListVersion: version,
+		Keys:        len(keys),
+	}
+	currentPrefix := fmt.Sprintf("%s:v%d:", bookListCacheKey, version)
+	for _, key := range keys {
+		switch {
+		case key == bookListVersionKey:
+		case strings.HasPrefix(key, bookListCacheKey+":v"):
+			stats.ListKeys++
+			if strings.HasPrefix(key, currentPrefix) {
+				stats.CurrentLists++
+			}
+		default:
+			stats.BookKeys++
+		}
+	}
+	return stats, nil
+}
+
+// CachedBook returns what the shared cache holds for a single book
+func (b *Books) CachedBook(ctx context.Context, ID int) (*domain.CachedBook, error) {
+	key := bookCacheKey(ID)
+	inspected := &domain.CachedBook{Key: key}
+
+	cachedData, err := b.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, cache.ErrMiss) {
+			return inspected, nil
+		}
+		return nil, cacheUnavailable(err)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(cachedData, &entry); err != nil {
+		return nil, err
+	}
+	inspected.Cached = true
+	inspected.NotFound = entry.NotFound
+	inspected.Stale = time.Now().After(entry.FreshUntil)
+	inspected.FreshUntil = &entry.FreshUntil
+	if !entry.NotFound {
+		if err := json.Unmarshal(entry.Data, &inspected.Book); err != nil {
+			return nil, err
+		}
+	}
+	return inspected, nil
+}
+
+// WarmCache loads up to count books, pageSize at a time, caching every page of the book list
+// that following the next cursors from the first page reads, and every book on it
+func (b *Books) WarmCache(ctx context.Context, count, pageSize int) (*domain.CacheWarmResult, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		return nil, domain.ErrCacheUnavailable
+	}
+
+	result := &domain.CacheWarmResult{}
+	query := domain.BookQuery{Limit: pageSize}.Normalize()
+	for result.Books < count {
+		list, err := b.loadBooks(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		b.storeValue(ctx, listCacheKey(version, query), list)
+		result.Pages++
+		for _, book := range list.Books {
+			b.storeValue(ctx, bookCacheKey(book.ID), book)
+			result.Books++
+		}
+		if !list.More {
+			break
+		}
+		query.Cursor = &domain.BookCursor{Book: *list.Books[len(list.Books)-1]}
+	}
+	return result, nil
+}
+
+// FlushCache deletes the whole book cache keyspace and clears the in-process cache of every instance
+func (b *Books) FlushCache(ctx context.Context) (*domain.CacheFlushResult, error) {
+	keys, err := b.cache.Keys(ctx, bookCacheKeyPattern)
+	if err != nil {
+		return nil, cacheUnavailable(err)
+	}
+	if len(keys) > 0 {
+		if err := b.cache.Del(ctx, keys...); err != nil {
+			return nil, cacheUnavailable(err)
+		}
+	}
+
+	b.local.Purge()
+	b.publishPurge(ctx)
+	return &domain.CacheFlushResult{Keys: len(keys)}, nil
+}
+
+func cacheUnavailable(err error) error {
+	fmt.Println("book cache call failed:", err)
+	return domain.ErrCacheUnavailable
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// cacheInvalidationChannel carries the keys each instance must drop from its in-process cache
+const cacheInvalidationChannel = "books:invalidate"
+
+// invalidationMessage names the keys to drop, or with All set asks for the whole cache to be dropped
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	All    bool     `json:"all,omitempty"`
+}
+
+func newInstanceID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func (b *Books) publishInvalidation(ctx context.Context, keys []string) {
+	b.publish(ctx, invalidationMessage{Origin: b.instanceID, Keys: keys})
+}
+
+func (b *Books) publishPurge(ctx context.Context) {
+	b.publish(ctx, invalidationMessage{Origin: b.instanceID, All: true})
+}
+
+func (b *Books) publish(ctx context.Context, invalidation invalidationMessage) {
+	if b.cacheConfig.LocalSize <= 0 {
+		return
+	}
+	message, _ := json.Marshal(invalidation)
+	b.cache.Publish(ctx, cacheInvalidationChannel, message)
+}
+
+// ListenForInvalidations drops keys changed by other instances from the in-process cache
+// until ctx is cancelled. The whole in-process cache is purged whenever the subscription
+// is (re)established, because invalidations sent while disconnected are lost.
+func (b *Books) ListenForInvalidations(ctx context.Context) {
+	if b.cacheConfig.LocalSize <= 0 {
+		return
+	}
+
+	b.cache.Subscribe(ctx, cacheInvalidationChannel, func(message []byte) {
+		var invalidation invalidationMessage
+		if err := json.Unmarshal(message, &invalidation); err != nil {
+			fmt.Println("invalid cache invalidation message:", err)
+			return
+		}
+		switch {
+		case invalidation.Origin == b.instanceID:
+		case invalidation.All:
+			b.local.Purge()
+		default:
+			b.local.Del(ctx, invalidation.Keys...)
+		}
+	}, b.local.Purge)
+}
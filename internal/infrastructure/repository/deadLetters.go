@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeadLetters struct {
+	gormDB *gorm.DB
+}
+
+func NewDeadLettersRepo(gormDB *gorm.DB) *DeadLetters {
+	return &DeadLetters{
+		gormDB: gormDB,
+	}
+}
+
+// GetDeadLetters lists dead-lettered events, most recent first
+func (d *DeadLetters) GetDeadLetters(ctx context.Context, offset, limit int) ([]*domain.DeadLetter, error) {
+	var deadLetters []*tables.DeadLetterEvents
+	if err := d.gormDB.WithContext(ctx).Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+
+	domainDeadLetters := make([]*domain.DeadLetter, 0, len(deadLetters))
+	for _, dl := range deadLetters {
+		domainDeadLetters = append(domainDeadLetters, dl.ToDomain())
+	}
+	return domainDeadLetters, nil
+}
+
+func (d *DeadLetters) GetDeadLetterByID(ctx context.Context, ID int64) (*domain.DeadLetter, error) {
+	var deadLetter tables.DeadLetterEvents
+	if err := d.gormDB.WithContext(ctx).Where("id = ?", ID).First(&deadLetter).Error; err != nil {
+		return nil, err
+	}
+	return deadLetter.ToDomain(), nil
+}
+
+// ReplayDeadLetter puts the event back into the outbox under its original ID, so the relay publishes it
+// ahead of the later events for the same book that waited behind it
+func (d *DeadLetters) ReplayDeadLetter(ctx context.Context, ID int64) error {
+	return d.resolve(ctx, ID, "replayed_at", func(tx *gorm.DB, deadLetter *tables.DeadLetterEvents) error {
+		return tx.Create(&tables.BookOutbox{
+			ID:          deadLetter.OutboxID,
+			AggregateID: deadLetter.AggregateID,
+			Topic:       deadLetter.Topic,
+			Payload:     deadLetter.Payload,
+		}).Error
+	})
+}
+
+// DiscardDeadLetter gives up on the event, releasing the later events for the same book
+func (d *DeadLetters) DiscardDeadLetter(ctx context.Context, ID int64) error {
+	return d.resolve(ctx, ID, "discarded_at", func(tx *gorm.DB, deadLetter *tables.DeadLetterEvents) error {
+		return nil
+	})
+}
+
+// resolve runs apply on an unresolved dead letter and stamps column with the current time.
+// The row is locked first, so two concurrent calls cannot both resolve it.
+func (d *DeadLetters) resolve(ctx context.Context, ID int64, column string, apply func(tx *gorm.DB, deadLetter *tables.DeadLetterEvents) error) error {
+	return d.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("id = ?", ID)
+		// SQLite has no row locks, and its single writer already serializes the transactions
+		if isPostgres(tx) {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var deadLetter tables.DeadLetterEvents
+		if err := query.First(&deadLetter).Error; err != nil {
+			return err
+		}
+		if deadLetter.ToDomain().Resolved() {
+			return domain.ErrDeadLetterResolved
+		}
+
+		if err := apply(tx, &deadLetter); err != nil {
+			return err
+		}
+		return tx.Model(&deadLetter).Update(column, time.Now()).Error
+	})
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// snapshotEvery controls how many events are stored between two snapshots of a book
+const snapshotEvery = 20
+
+// EventSourcedBooks stores every change to a book as an event and rebuilds books from them.
+// The current state of every live book is also kept in book_event_state, in the same transaction,
+// which lists are read from and whose unique index rejects duplicate books.
+// Concurrent writers to the same book are rejected by the (book_id, version) unique key.
+type EventSourcedBooks struct {
+	gormDB *gorm.DB
+}
+
+func NewEventSourcedBooksRepo(gormDB *gorm.DB) *EventSourcedBooks {
+	return &EventSourcedBooks{
+		gormDB: gormDB,
+	}
+}
+
+// GetBooks returns the page of books selected by query. A query that filters or sorts by anything
+// but the ID, or pages backwards, reads every book and selects them in process.
+func (e *EventSourcedBooks) GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookList, error) {
+	if !query.Unfiltered() || (query.Cursor != nil && query.Cursor.Before) {
+		books, err := e.allBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return queryBooks(books, query), nil
+	}
+
+	afterID := 0
+	if query.Cursor != nil {
+		afterID = query.Cursor.Book.ID
+	}
+	bookIDs, err := e.liveBookIDs(ctx, afterID, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	books, err := e.loadBooks(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	list := &domain.BookList{}
+	list.Books, list.More = pageOf(books, query.Limit, false)
+	return list, nil
+}
+
+// CountBooks counts the books matching the filters of query
+func (e *EventSourcedBooks) CountBooks(ctx context.Context, query domain.BookQuery) (int64, error) {
+	if query.Unfiltered() {
+		bookIDs, err := e.liveBookIDs(ctx, 0, -1)
+		return int64(len(bookIDs)), err
+	}
+
+	books, err := e.allBooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return countMatching(books, query), nil
+}
+
+// GetBookFacets counts the books matching the filters of query by author, up to authors of them, and by decade and year.
+// Like a filtered list, it reads every book.
+func (e *EventSourcedBooks) GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error) {
+	books, err := e.allBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bookFacets(books, facetQuery(query), authors), nil
+}
+
+// allBooks reads every book that is neither deleted nor merged away
+func (e *EventSourcedBooks) allBooks(ctx context.Context) ([]*domain.Book, error) {
+	var states []*tables.BookEventState
+	if err := e.gormDB.WithContext(ctx).Order("book_id").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return booksOf(states), nil
+}
+
+// liveBookIDs selects, in ID order, up to limit IDs above afterID of the books that are neither deleted
+// nor merged away. A negative limit selects all of them.
+func (e *EventSourcedBooks) liveBookIDs(ctx context.Context, afterID, limit int) ([]int, error) {
+	var bookIDs []int
+	if err := e.gormDB.WithContext(ctx).Model(&tables.BookEventState{}).
+		Where("book_id > ?", afterID).
+		Order("book_id").
+		Limit(limit).
+		Pluck("book_id", &bookIDs).Error; err != nil {
+		return nil, err
+	}
+	return bookIDs, nil
+}
+
+// loadBooks reads the current state of the books with the given IDs, in ID order
+func (e *EventSourcedBooks) loadBooks(ctx context.Context, bookIDs []int) ([]*domain.Book, error) {
+	if len(bookIDs) == 0 {
+		return []*domain.Book{}, nil
+	}
+	var states []*tables.BookEventState
+	if err := e.gormDB.WithContext(ctx).Where("book_id IN ?", bookIDs).Order("book_id").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return booksOf(states), nil
+}
+
+func booksOf(states []*tables.BookEventState) []*domain.Book {
+	books := make([]*domain.Book, 0, len(states))
+	for _, state := range states {
+		books = append(books, state.ToDomain())
+	}
+	return books
+}
+
+func (e *EventSourcedBooks) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
+	return e.GetBookAt(ctx, ID, time.Time{})
+}
+
+// GetBookAt returns the book as it was at the given time, or its current state for the zero time
+func (e *EventSourcedBooks) GetBookAt(ctx context.Context, ID int, at time.Time) (*domain.Book, error) {
+	var until *time.Time
+	if !at.IsZero() {
+		until = &at
+	}
+
+	aggregate, err := e.load(e.gormDB.WithContext(ctx), ID, until)
+	if err != nil {
+		return nil, err
+	}
+	if !aggregate.Exists() {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &aggregate.Book, nil
+}
+
+// CreateBook stores the creation of book. A live book with the same normalized title and author
+// is rejected by the unique index on book_event_state, which the database reports as gorm.ErrDuplicatedKey.
+func (e *EventSourcedBooks) CreateBook(ctx context.Context, book *domain.Book) error {
+	return e.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ID int
+		if err := tx.Raw("SELECT nextval('book_event_store_book_id_seq')").Scan(&ID).Error; err != nil {
+			return err
+		}
+
+		created := domain.Book{
+			ID:     ID,
+			Title:  book.Title,
+			Author: book.Author,
+			Year:   book.Year,
+		}
+		if err := e.append(tx, &domain.BookAggregate{}, domain.BookCreated{After: created}); err != nil {
+			return err
+		}
+		book.ID = ID
+		return nil
+	})
+}
+
+func (e *EventSourcedBooks) UpdateBookByID(ctx context.Context, ID int, book domain.Book) error {
+	return e.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		aggregate, err := e.load(tx, ID, nil)
+		if err != nil {
+			return err
+		}
+		if !aggregate.Exists() {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Like a GORM struct update, only non-zero fields are changed
+		after := aggregate.Book
+		if book.Title != "" {
+			after.Title = book.Title
+		}
+		if book.Author != "" {
+			after.Author = book.Author
+		}
+		if book.Year != 0 {
+			after.Year = book.Year
+		}
+		return e.append(tx, aggregate, domain.BookUpdated{Before: aggregate.Book, After: after})
+	})
+}
+
+func (e *EventSourcedBooks) DeleteBookByID(ctx context.Context, ID int) error {
+	return e.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		aggregate, err := e.load(tx, ID, nil)
+		if err != nil {
+			return err
+		}
+		// Nothing to delete, so there is nothing to announce
+		if !aggregate.Exists() {
+			return nil
+		}
+		return e.append(tx, aggregate, domain.BookDeleted{Before: aggregate.Book})
+	})
+}
+
+// MergeBooks merges the source book into the target book. The merge event is stored for both books,
+// which leaves the source book deleted.
+func (e *EventSourcedBooks) MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error) {
+	var merged domain.Book
+	err := e.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target, err := e.load(tx, targetID, nil)
+		if err != nil {
+			return err
+		}
+		source, err := e.load(tx, request.SourceID, nil)
+		if err != nil {
+			return err
+		}
+		if !target.Exists() || !source.Exists() {
+			return gorm.ErrRecordNotFound
+		}
+
+		merged = request.Merge(target.Book, source.Book)
+		event := domain.BookMerged{Before: target.Book, After: merged, Source: source.Book}
+		envelope, err := domain.NewCloudEvent(event)
+		if err != nil {
+			return err
+		}
+		// The source goes first, so its state no longer holds the title and author the target may take over
+		if err := e.store(tx, request.SourceID, source, envelope, event); err != nil {
+			return err
+		}
+		if err := e.store(tx, targetID, target, envelope, event); err != nil {
+			return err
+		}
+		return enqueueEnvelope(tx, event.BookID(), envelope)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// load rebuilds a book from its latest snapshot and the events after it, ignoring anything after until
+func (e *EventSourcedBooks) load(tx *gorm.DB, ID int, until *time.Time) (*domain.BookAggregate, error) {
+	aggregate := &domain.BookAggregate{}
+
+	snapshotQuery := tx.Where("book_id = ?", ID)
+	if until != nil {
+		snapshotQuery = snapshotQuery.Where("occurred_at <= ?", *until)
+	}
+	var snapshot tables.BookSnapshots
+	err := snapshotQuery.Order("version DESC").First(&snapshot).Error
+	switch {
+	case err == nil:
+		if err := json.Unmarshal([]byte(snapshot.State), &aggregate.Book); err != nil {
+			return nil, err
+		}
+		aggregate.Version = snapshot.Version
+		aggregate.Deleted = snapshot.Deleted
+		aggregate.OccurredAt = snapshot.OccurredAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	eventQuery := tx.Where("book_id = ? AND version > ?", ID, aggregate.Version)
+	if until != nil {
+		eventQuery = eventQuery.Where("occurred_at <= ?", *until)
+	}
+	var events []*tables.BookEventStore
+	if err := eventQuery.Order("version").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for _, stored := range events {
+		envelope, event, err := domain.DecodeCloudEvent([]byte(stored.Payload))
+		if err != nil {
+			return nil, err
+		}
+		aggregate.Apply(event, envelope.Time)
+	}
+
+	if aggregate.Version == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return aggregate, nil
+}
+
+// append stores event as the next version of aggregate and queues it for publishing
+func (e *EventSourcedBooks) append(tx *gorm.DB, aggregate *domain.BookAggregate, event domain.BookEvent) error {
+	envelope, err := domain.NewCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	if err := e.store(tx, event.BookID(), aggregate, envelope, event); err != nil {
+		return err
+	}
+	return enqueueEnvelope(tx, event.BookID(), envelope)
+}
+
+// store records event as the next version of the book with ID bookID, updates its state and snapshots it when due.
+// A version stored by a concurrent writer since aggregate was loaded is reported as domain.ErrConcurrentModification.
+func (e *EventSourcedBooks) store(tx *gorm.DB, bookID int, aggregate *domain.BookAggregate, envelope *domain.CloudEvent, event domain.BookEvent) error {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	aggregate.Apply(event, envelope.Time)
+	if err := tx.Create(&tables.BookEventStore{
+		BookID:     bookID,
+		Version:    aggregate.Version,
+		EventID:    envelope.ID,
+		EventType:  envelope.Type,
+		Payload:    string(payload),
+		OccurredAt: envelope.Time,
+	}).Error; err != nil {
+		// Only (book_id, version) is unique, so another writer stored this version first
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConcurrentModification
+		}
+		return err
+	}
+	if err := saveState(tx, bookID, aggregate); err != nil {
+		return err
+	}
+
+	if aggregate.Version%snapshotEvery != 0 {
+		return nil
+	}
+	state, err := json.Marshal(aggregate.Book)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&tables.BookSnapshots{
+		BookID:     bookID,
+		Version:    aggregate.Version,
+		State:      string(state),
+		Deleted:    aggregate.Deleted,
+		OccurredAt: aggregate.OccurredAt,
+	}).Error
+}
+
+// saveState writes the state of a live book to book_event_state and removes a deleted one
+func saveState(tx *gorm.DB, bookID int, aggregate *domain.BookAggregate) error {
+	if aggregate.Deleted {
+		return tx.Where("book_id = ?", bookID).Delete(&tables.BookEventState{}).Error
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"version", "title", "author", "year", "title_normalized", "author_normalized"}),
+	}).Create(tables.NewBookEventState(aggregate.Book, aggregate.Version)).Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"gorm.io/gorm"
+)
+
+// GetBookFacets counts the books matching the filters of query by author, up to authors of them, and by decade and year.
+// The database groups the books, so only the counts are read.
+func (b *Books) GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error) {
+	query = facetQuery(query)
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.loadFacets(ctx, query, authors)
+	}
+	key := fmt.Sprintf("%s:v%d:facets:%d:%s", bookListCacheKey, version, authors, query.FilterKey())
+	return cached(ctx, b, key, func(ctx context.Context) (*domain.BookFacets, error) {
+		return b.loadFacets(ctx, query, authors)
+	})
+}
+
+func (b *Books) loadFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error) {
+	// Both counts are read from one snapshot, so a concurrent write cannot make the facets disagree.
+	// SQLite transactions are serializable already.
+	var options []*sql.TxOptions
+	if isPostgres(b.gormDB) {
+		options = append(options, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	}
+
+	var authorCounts []domain.AuthorCount
+	var yearCounts []domain.YearCount
+	err := b.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := filterBooks(tx.Model(&tables.Books{}), query).
+			Select("min(author) AS author, count(*) AS books").
+			Group("author_normalized").
+			Order("count(*) DESC, author_normalized").
+			Limit(authors).
+			Scan(&authorCounts).Error
+		if err != nil {
+			return err
+		}
+
+		return filterBooks(tx.Model(&tables.Books{}), query).
+			Select("year, count(*) AS books").
+			Group("year").
+			Order("year").
+			Scan(&yearCounts).Error
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewBookFacets(authorCounts, yearCounts), nil
+}
+
+// facetQuery keeps the filters of query and drops its sort and page, which do not change the counts
+func facetQuery(query domain.BookQuery) domain.BookQuery {
+	query.Sort, query.Cursor, query.Limit, query.Total = nil, nil, 0, false
+	return query
+}
+
+// bookFacets counts the books matching the filters of the normalized query in process, like Books.loadFacets
+func bookFacets(books []*domain.Book, query domain.BookQuery, authors int) *domain.BookFacets {
+	byAuthor := make(map[string]*domain.AuthorCount)
+	byYear := make(map[int]int64)
+	for _, book := range books {
+		if !query.Matches(*book) {
+			continue
+		}
+		byYear[book.Year]++
+		normalized := domain.NormalizeText(book.Author)
+		if count, ok := byAuthor[normalized]; ok {
+			count.Books++
+			count.Author = min(count.Author, book.Author)
+			continue
+		}
+		byAuthor[normalized] = &domain.AuthorCount{Author: book.Author, Books: 1}
+	}
+
+	normalizedAuthors := make([]string, 0, len(byAuthor))
+	for normalized := range byAuthor {
+		normalizedAuthors = append(normalizedAuthors, normalized)
+	}
+	slices.SortFunc(normalizedAuthors, func(a, b string) int {
+		return cmp.Or(cmp.Compare(byAuthor[b].Books, byAuthor[a].Books), strings.Compare(a, b))
+	})
+	if len(normalizedAuthors) > authors {
+		normalizedAuthors = normalizedAuthors[:authors]
+	}
+	authorCounts := make([]domain.AuthorCount, 0, len(normalizedAuthors))
+	for _, normalized := range normalizedAuthors {
+		authorCounts = append(authorCounts, *byAuthor[normalized])
+	}
+
+	yearCounts := make([]domain.YearCount, 0, len(byYear))
+	for year, count := range byYear {
+		yearCounts = append(yearCounts, domain.YearCount{Year: year, Books: count})
+	}
+	slices.SortFunc(yearCounts, func(a, b domain.YearCount) int { return a.Year - b.Year })
+	return domain.NewBookFacets(authorCounts, yearCounts)
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
+
+// MemoryBooks keeps books in process, for tests and for trying the service out without a database.
+// It reports missing and duplicate books with the same gorm errors as Books, and writes book events
+// to a MemoryOutbox.
+type MemoryBooks struct {
+	mu     sync.RWMutex
+	books  map[int]domain.Book
+	nextID int
+	outbox *MemoryOutbox
+}
+
+// NewMemoryBooksRepo returns an empty repository writing its events to outbox, or to a new outbox when nil
+func NewMemoryBooksRepo(outbox *MemoryOutbox) *MemoryBooks {
+	if outbox == nil {
+		outbox = NewMemoryOutbox()
+	}
+	return &MemoryBooks{
+		books:  make(map[int]domain.Book),
+		outbox: outbox,
+	}
+}
+
+// GetBooks returns the page of books selected by query
+func (m *MemoryBooks) GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookList, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return queryBooks(m.sorted(), query), nil
+}
+
+// CountBooks counts the books matching the filters of query
+func (m *MemoryBooks) CountBooks(ctx context.Context, query domain.BookQuery) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return countMatching(m.sorted(), query), nil
+}
+
+// SearchBooks returns the page of books matching the full-text search, most relevant first
+func (m *MemoryBooks) SearchBooks(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchList, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return searchBooks(m.sorted(), query), nil
+}
+
+// CountSearchResults counts the books matching the full-text search
+func (m *MemoryBooks) CountSearchResults(ctx context.Context, query domain.BookSearchQuery) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return countSearchMatches(m.sorted(), query), nil
+}
+
+// SuggestBooks returns the titles and authors completing the prefix, best completion first
+func (m *MemoryBooks) SuggestBooks(ctx context.Context, query domain.BookSuggestQuery) ([]*domain.BookSuggestion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return suggestBooks(m.sorted(), query), nil
+}
+
+// GetBookFacets counts the books matching the filters of query by author, up to authors of them, and by decade and year
+func (m *MemoryBooks) GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return bookFacets(m.sorted(), facetQuery(query), authors), nil
+}
+
+func (m *MemoryBooks) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	book, ok := m.books[ID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &book, nil
+}
+
+// FindSimilarBooks returns up to limit books whose title is similar to title, most similar first
+func (m *MemoryBooks) FindSimilarBooks(ctx context.Context, title string, limit int) ([]*domain.Book, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return similarBooks(m.sorted(), title, limit), nil
+}
+
+func (m *MemoryBooks) CreateBook(ctx context.Context, book *domain.Book) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	created := *book
+	created.ID = m.nextID + 1
+	if m.duplicate(created) {
+		return gorm.ErrDuplicatedKey
+	}
+	if err := m.outbox.enqueue(domain.BookCreated{After: created}); err != nil {
+		return err
+	}
+	m.nextID++
+	m.books[created.ID] = created
+	book.ID = created.ID
+	return nil
+}
+
+// UpdateBookByID changes the fields that are set in book
+func (m *MemoryBooks) UpdateBookByID(ctx context.Context, ID int, book domain.Book) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before, ok := m.books[ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	after := before
+	if book.Title != "" {
+		after.Title = book.Title
+	}
+	if book.Author != "" {
+		after.Author = book.Author
+	}
+	if book.Year != 0 {
+		after.Year = book.Year
+	}
+	if m.duplicate(after) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	if err := m.outbox.enqueue(domain.BookUpdated{Before: before, After: after}); err != nil {
+		return err
+	}
+	m.books[ID] = after
+	return nil
+}
+
+func (m *MemoryBooks) DeleteBookByID(ctx context.Context, ID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before, ok := m.books[ID]
+	// Nothing to delete, so there is nothing to announce
+	if !ok {
+		return nil
+	}
+	if err := m.outbox.enqueue(domain.BookDeleted{Before: before}); err != nil {
+		return err
+	}
+	delete(m.books, ID)
+	return nil
+}
+
+// MergeBooks merges the source book into the target book and removes the source book
+func (m *MemoryBooks) MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target, targetFound := m.books[targetID]
+	source, sourceFound := m.books[request.SourceID]
+	if !targetFound || !sourceFound {
+		return nil, gorm.ErrRecordNotFound
+	}
+	merged := request.Merge(target, source)
+	if m.duplicate(merged, source.ID) {
+		return nil, gorm.ErrDuplicatedKey
+	}
+
+	if err := m.outbox.enqueue(domain.BookMerged{Before: target, After: merged, Source: source}); err != nil {
+		return nil, err
+	}
+	delete(m.books, source.ID)
+	m.books[targetID] = merged
+	return &merged, nil
+}
+
+// duplicate reports whether another book has the normalized title and author of book, like the unique index of Books.
+// The books with the ignored IDs are left out.
+func (m *MemoryBooks) duplicate(book domain.Book, ignored ...int) bool {
+	title, author := domain.NormalizeText(book.Title), domain.NormalizeText(book.Author)
+	for _, existing := range m.books {
+		if existing.ID == book.ID || slices.Contains(ignored, existing.ID) {
+			continue
+		}
+		if domain.NormalizeText(existing.Title) == title && domain.NormalizeText(existing.Author) == author {
+			return true
+		}
+	}
+	return false
+}
+
+// sorted returns copies of all books ordered by ID
+func (m *MemoryBooks) sorted() []*domain.Book {
+	books := make([]*domain.Book, 0, len(m.books))
+	for _, book := range m.books {
+		book := book
+		books = append(books, &book)
+	}
+	sort.Slice(books, func(i, j int) bool {
+		return books[i].ID < books[j].ID
+	})
+	return books
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+func TestMemoryBooks(t *testing.T) {
+	servicetest.TestBookRepo(t, func(t *testing.T) service.BookRepo {
+		return repository.NewMemoryBooksRepo(nil)
+	})
+}
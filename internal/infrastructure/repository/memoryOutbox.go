@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
+
+// MemoryOutbox is the outbox and dead letter store of MemoryBooks. It relays and dead-letters
+// messages like Outbox and DeadLetters do, and loses them when the process exits.
+type MemoryOutbox struct {
+	relayMu            sync.Mutex
+	mu                 sync.Mutex
+	pending            []*memoryOutboxMessage
+	deadLetters        []*domain.DeadLetter
+	nextID             int64
+	nextDeadLetterID   int64
+	deadLettersPending int64
+}
+
+type memoryOutboxMessage struct {
+	message       domain.OutboxMessage
+	nextAttemptAt time.Time
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+// enqueue wraps event in a CloudEvents envelope and queues it for the relay
+func (o *MemoryOutbox) enqueue(event domain.BookEvent) error {
+	envelope, err := domain.NewCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.add(event.BookID(), domain.BookEventsTopic, payload)
+	return nil
+}
+
+func (o *MemoryOutbox) add(aggregateID int, topic string, payload json.RawMessage) {
+	o.nextID++
+	o.insert(o.nextID, aggregateID, topic, payload)
+}
+
+// insert queues a message with the given ID, keeping pending in ID order
+func (o *MemoryOutbox) insert(ID int64, aggregateID int, topic string, payload json.RawMessage) {
+	now := time.Now()
+	i, _ := slices.BinarySearchFunc(o.pending, ID, func(m *memoryOutboxMessage, ID int64) int {
+		return cmp.Compare(m.message.ID, ID)
+	})
+	o.pending = slices.Insert(o.pending, i, &memoryOutboxMessage{
+		message: domain.OutboxMessage{
+			ID:          ID,
+			AggregateID: aggregateID,
+			Topic:       topic,
+			Payload:     payload,
+			CreatedAt:   now,
+		},
+		nextAttemptAt: now,
+	})
+}
+
+// ProcessPending hands up to limit due messages to publish in insertion order, with the
+// same ordering and retry rules as Outbox.ProcessPending. Published messages are dropped.
+// The batch is taken and its outcomes recorded under the lock, but publish runs without it,
+// so writers are never blocked by a slow event bus.
+func (o *MemoryOutbox) ProcessPending(ctx context.Context, limit int, policy domain.RetryPolicy, publish func(*domain.OutboxMessage) error) (domain.RelayResult, error) {
+	// Like the advisory lock of Outbox, lets a single relay run at a time
+	o.relayMu.Lock()
+	defer o.relayMu.Unlock()
+
+	var result domain.RelayResult
+	failures := make(map[int64]error)
+	published := make(map[int64]bool)
+	blocked := make(map[int]bool)
+	for _, message := range o.due(limit) {
+		// Later messages for a book wait for its failed message
+		if blocked[message.AggregateID] {
+			continue
+		}
+		if err := publish(&message); err != nil {
+			result.Failed++
+			failures[message.ID] = err
+			blocked[message.AggregateID] = true
+			continue
+		}
+		result.Published++
+		published[message.ID] = true
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	remaining := o.pending[:0]
+	for _, m := range o.pending {
+		if published[m.message.ID] {
+			continue
+		}
+		publishErr, failed := failures[m.message.ID]
+		if !failed {
+			remaining = append(remaining, m)
+			continue
+		}
+
+		attempts := m.message.Attempts + 1
+		if policy.Exhausted(attempts) {
+			result.DeadLettered++
+			o.nextDeadLetterID++
+			o.deadLettersPending++
+			o.deadLetters = append(o.deadLetters, &domain.DeadLetter{
+				ID:             o.nextDeadLetterID,
+				OutboxID:       m.message.ID,
+				AggregateID:    m.message.AggregateID,
+				Topic:          m.message.Topic,
+				Payload:        m.message.Payload,
+				Error:          publishErr.Error(),
+				Attempts:       attempts,
+				CreatedAt:      m.message.CreatedAt,
+				DeadLetteredAt: now,
+			})
+			continue
+		}
+
+		m.message.Attempts = attempts
+		m.nextAttemptAt = now.Add(policy.Backoff(attempts))
+		remaining = append(remaining, m)
+	}
+	o.pending = remaining
+	return result, nil
+}
+
+// due returns copies of up to limit messages that are due, skipping books whose earlier
+// messages are backing off or waiting in the dead letters
+func (o *MemoryOutbox) due(limit int) []domain.OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	blocked := make(map[int]bool)
+	var messages []domain.OutboxMessage
+	for _, m := range o.pending {
+		if len(messages) >= limit {
+			break
+		}
+		if m.nextAttemptAt.After(now) || o.heldBack(&m.message) {
+			blocked[m.message.AggregateID] = true
+		}
+		if blocked[m.message.AggregateID] {
+			continue
+		}
+		messages = append(messages, m.message)
+	}
+	return messages
+}
+
+// Stats returns the size and age of the unpublished backlog
+func (o *MemoryOutbox) Stats(ctx context.Context) (*domain.OutboxStats, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	stats := &domain.OutboxStats{
+		Pending:            int64(len(o.pending)),
+		DeadLettersPending: o.deadLettersPending,
+	}
+	if len(o.pending) > 0 {
+		oldest := o.pending[0].message.CreatedAt
+		stats.OldestPendingAt = &oldest
+		stats.LagSeconds = time.Since(oldest).Seconds()
+	}
+	return stats, nil
+}
+
+// GetDeadLetters lists dead-lettered events, most recent first
+func (o *MemoryOutbox) GetDeadLetters(ctx context.Context, offset, limit int) ([]*domain.DeadLetter, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	deadLetters := make([]*domain.DeadLetter, 0, limit)
+	for i := len(o.deadLetters) - 1 - offset; i >= 0 && len(deadLetters) < limit; i-- {
+		deadLetter := *o.deadLetters[i]
+		deadLetters = append(deadLetters, &deadLetter)
+	}
+	return deadLetters, nil
+}
+
+func (o *MemoryOutbox) GetDeadLetterByID(ctx context.Context, ID int64) (*domain.DeadLetter, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	deadLetter, ok := o.deadLetter(ID)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	result := *deadLetter
+	return &result, nil
+}
+
+// ReplayDeadLetter puts the event back into the outbox under its original ID, so the relay publishes it
+// ahead of the later events for the same book that waited behind it
+func (o *MemoryOutbox) ReplayDeadLetter(ctx context.Context, ID int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	deadLetter, err := o.unresolvedDeadLetter(ID)
+	if err != nil {
+		return err
+	}
+	o.insert(deadLetter.OutboxID, deadLetter.AggregateID, deadLetter.Topic, deadLetter.Payload)
+	o.deadLettersPending--
+	now := time.Now()
+	deadLetter.ReplayedAt = &now
+	return nil
+}
+
+// DiscardDeadLetter gives up on the event, releasing the later events for the same book
+func (o *MemoryOutbox) DiscardDeadLetter(ctx context.Context, ID int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	deadLetter, err := o.unresolvedDeadLetter(ID)
+	if err != nil {
+		return err
+	}
+	o.deadLettersPending--
+	now := time.Now()
+	deadLetter.DiscardedAt = &now
+	return nil
+}
+
+func (o *MemoryOutbox) unresolvedDeadLetter(ID int64) (*domain.DeadLetter, error) {
+	deadLetter, ok := o.deadLetter(ID)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if deadLetter.Resolved() {
+		return nil, domain.ErrDeadLetterResolved
+	}
+	return deadLetter, nil
+}
+
+func (o *MemoryOutbox) deadLetter(ID int64) (*domain.DeadLetter, bool) {
+	for _, deadLetter := range o.deadLetters {
+		if deadLetter.ID == ID {
+			return deadLetter, true
+		}
+	}
+	return nil, false
+}
+
+// heldBack reports whether an earlier message for the same book is waiting in the dead letters
+func (o *MemoryOutbox) heldBack(message *domain.OutboxMessage) bool {
+	for _, deadLetter := range o.deadLetters {
+		if deadLetter.AggregateID == message.AggregateID && deadLetter.OutboxID < message.ID && !deadLetter.Resolved() {
+			return true
+		}
+	}
+	return false
+}
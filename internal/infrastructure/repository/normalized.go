@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"gorm.io/gorm"
+)
+
+const backfillBatchSize = 500
+
+// normalizedTables hold a title_normalized and author_normalized column, keyed by their primary key
+var normalizedTables = []struct{ table, key string }{
+	{table: "books", key: "id"},
+	{table: "book_event_state", key: "book_id"},
+}
+
+// normalizedRow is a row of one of normalizedTables, with its key read as id
+type normalizedRow struct {
+	ID               int    `gorm:"column:id"`
+	Title            string `gorm:"column:title"`
+	Author           string `gorm:"column:author"`
+	TitleNormalized  string `gorm:"column:title_normalized"`
+	AuthorNormalized string `gorm:"column:author_normalized"`
+}
+
+// BackfillNormalized sets title_normalized and author_normalized to domain.NormalizeText of the title and
+// author wherever they differ. The migrations fill both columns for existing rows in SQL, whose lower and
+// regexp_replace fold case and whitespace differently from Go for non-ASCII text. Tables without the columns
+// are skipped. It returns how many rows were updated.
+func BackfillNormalized(ctx context.Context, gormDB *gorm.DB) (int, error) {
+	updated := 0
+	for _, t := range normalizedTables {
+		if !gormDB.Migrator().HasColumn(t.table, "title_normalized") {
+			continue
+		}
+		n, err := backfillNormalizedTable(ctx, gormDB, t.table, t.key)
+		updated += n
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill %s: %w", t.table, err)
+		}
+	}
+	return updated, nil
+}
+
+// backfillNormalizedTable walks table in batches by key, soft deleted books included
+func backfillNormalizedTable(ctx context.Context, gormDB *gorm.DB, table, key string) (int, error) {
+	updated, lastID := 0, 0
+	for {
+		var rows []normalizedRow
+		err := gormDB.WithContext(ctx).Table(table).
+			Select(key+" AS id, title, author, title_normalized, author_normalized").
+			Where(key+" > ?", lastID).
+			Order(key).
+			Limit(backfillBatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return updated, err
+		}
+
+		for _, row := range rows {
+			title, author := domain.NormalizeText(row.Title), domain.NormalizeText(row.Author)
+			if title == row.TitleNormalized && author == row.AuthorNormalized {
+				continue
+			}
+			err := gormDB.WithContext(ctx).Table(table).
+				Where(key+" = ?", row.ID).
+				Updates(map[string]any{"title_normalized": title, "author_normalized": author}).Error
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return updated, fmt.Errorf("%s %d is a duplicate once normalized and has to be merged or removed first: %w", key, row.ID, err)
+			}
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+
+		if len(rows) < backfillBatchSize {
+			return updated, nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,48 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+)
+
+// TestBackfillNormalized stores rows normalized the way the migrations do in SQL, where lower leaves
+// non-ASCII letters alone and \s misses the no-break space, and checks that they end up as the app writes them
+func TestBackfillNormalized(t *testing.T) {
+	gormDB := openSQLite(t)
+	rows := []tables.Books{
+		{Title: "L'ÉCOLE\u00a0DES  Femmes", Author: "Molière", TitleNormalized: "l'École\u00a0des femmes", AuthorNormalized: "molière"},
+		{Title: "The  Hobbit", Author: "J.R.R. Tolkien", TitleNormalized: "the hobbit", AuthorNormalized: "j.r.r. tolkien"},
+	}
+	if err := gormDB.Create(&rows).Error; err != nil {
+		t.Fatalf("create books: %v", err)
+	}
+
+	updated, err := repository.BackfillNormalized(context.Background(), gormDB)
+	if err != nil {
+		t.Fatalf("BackfillNormalized: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("BackfillNormalized updated %d rows, want 1", updated)
+	}
+
+	var got []tables.Books
+	if err := gormDB.Order("id").Find(&got).Error; err != nil {
+		t.Fatalf("find books: %v", err)
+	}
+	for _, book := range got {
+		if want := domain.NormalizeText(book.Title); book.TitleNormalized != want {
+			t.Errorf("book %d title_normalized = %q, want %q", book.ID, book.TitleNormalized, want)
+		}
+		if want := domain.NormalizeText(book.Author); book.AuthorNormalized != want {
+			t.Errorf("book %d author_normalized = %q, want %q", book.ID, book.AuthorNormalized, want)
+		}
+	}
+
+	if updated, err := repository.BackfillNormalized(context.Background(), gormDB); err != nil || updated != 0 {
+		t.Errorf("second BackfillNormalized = %d, %v, want 0, nil", updated, err)
+	}
+}
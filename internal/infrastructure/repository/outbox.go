@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"gorm.io/gorm"
+)
+
+// outboxRelayLockID is the advisory lock key that keeps a single relay draining the outbox,
+// so events for the same book are never published out of order by two instances
+const outboxRelayLockID = 7_000_001
+
+type Outbox struct {
+	gormDB *gorm.DB
+}
+
+func NewOutboxRepo(gormDB *gorm.DB) *Outbox {
+	return &Outbox{
+		gormDB: gormDB,
+	}
+}
+
+// enqueueEvent wraps event in a CloudEvents envelope and stores it in the outbox using the caller's transaction
+func enqueueEvent(tx *gorm.DB, event domain.BookEvent) error {
+	envelope, err := domain.NewCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	return enqueueEnvelope(tx, event.BookID(), envelope)
+}
+
+func enqueueEnvelope(tx *gorm.DB, aggregateID int, envelope *domain.CloudEvent) error {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&tables.BookOutbox{
+		AggregateID: aggregateID,
+		Topic:       domain.BookEventsTopic,
+		Payload:     string(payload),
+	}).Error
+}
+
+// ProcessPending hands up to limit due messages to publish in insertion order.
+// Once a message fails, later messages for the same book are held back until it is published
+// or, after policy runs out of attempts, moved to dead_letter_events and then replayed or discarded.
+// No transaction is open while publish runs, so a slow broker never holds row locks,
+// and each outcome is recorded on its own straight after the call returns.
+func (o *Outbox) ProcessPending(ctx context.Context, limit int, policy domain.RetryPolicy, publish func(*domain.OutboxMessage) error) (domain.RelayResult, error) {
+	db := o.gormDB.WithContext(ctx)
+	// A SQLite database is only served by one instance, so it needs no lock
+	if !isPostgres(db) {
+		return o.processPending(db, limit, policy, publish)
+	}
+
+	// The session lock lives on one pooled connection, so every statement of the run is pinned to it
+	var result domain.RelayResult
+	err := db.Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", outboxRelayLockID).Scan(&locked).Error; err != nil {
+			return err
+		}
+		// Another relay is draining the outbox
+		if !locked {
+			return nil
+		}
+		defer func() {
+			// A connection going back to the pool still holding the lock would keep every later relay out
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", outboxRelayLockID).Error; err != nil {
+				fmt.Println("failed to release outbox relay lock, closing its connection:", err)
+				discardConnection(conn)
+			}
+		}()
+
+		var err error
+		result, err = o.processPending(conn, limit, policy, publish)
+		return err
+	})
+	return result, err
+}
+
+// discardConnection makes database/sql close the connection conn is pinned to instead of pooling it again,
+// which ends its session and with it every session lock it holds
+func discardConnection(conn *gorm.DB) {
+	if sqlConn, ok := conn.Statement.ConnPool.(*sql.Conn); ok {
+		sqlConn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
+
+func (o *Outbox) processPending(db *gorm.DB, limit int, policy domain.RetryPolicy, publish func(*domain.OutboxMessage) error) (domain.RelayResult, error) {
+	var result domain.RelayResult
+
+	// Skip books whose earlier messages are still backing off or waiting in dead_letter_events
+	now := time.Now()
+	var messages []*tables.BookOutbox
+	if err := db.Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM book_outbox earlier
+			WHERE earlier.aggregate_id = book_outbox.aggregate_id
+			AND earlier.published_at IS NULL
+			AND earlier.id < book_outbox.id
+			AND earlier.next_attempt_at > ?)`, now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM dead_letter_events dead
+			WHERE dead.aggregate_id = book_outbox.aggregate_id
+			AND dead.outbox_id < book_outbox.id
+			AND dead.replayed_at IS NULL
+			AND dead.discarded_at IS NULL)`).
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return result, err
+	}
+
+	blocked := make(map[int]bool)
+	for _, m := range messages {
+		if blocked[m.AggregateID] {
+			continue
+		}
+
+		// A message published here but not marked below is sent again by the next run,
+		// which at-least-once delivery already allows
+		publishErr := publish(m.ToDomain())
+		if publishErr == nil {
+			result.Published++
+			if err := db.Model(m).Update("published_at", time.Now()).Error; err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		result.Failed++
+		attempts := m.Attempts + 1
+		if policy.Exhausted(attempts) {
+			result.DeadLettered++
+			blocked[m.AggregateID] = true
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return moveToDeadLetters(tx, m, attempts, publishErr)
+			}); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		blocked[m.AggregateID] = true
+		if err := db.Model(m).Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      publishErr.Error(),
+			"next_attempt_at": time.Now().Add(policy.Backoff(attempts)),
+		}).Error; err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func moveToDeadLetters(tx *gorm.DB, m *tables.BookOutbox, attempts int, publishErr error) error {
+	if err := tx.Create(&tables.DeadLetterEvents{
+		OutboxID:    m.ID,
+		AggregateID: m.AggregateID,
+		Topic:       m.Topic,
+		Payload:     m.Payload,
+		Error:       publishErr.Error(),
+		Attempts:    attempts,
+		CreatedAt:   m.CreatedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(m).Error
+}
+
+// Stats returns the size and age of the unpublished backlog
+func (o *Outbox) Stats(ctx context.Context) (*domain.OutboxStats, error) {
+	var pending int64
+	if err := o.gormDB.WithContext(ctx).Model(&tables.BookOutbox{}).Where("published_at IS NULL").Count(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	// The oldest message is read as a row rather than with MIN, which SQLite returns as text
+	var oldest []*tables.BookOutbox
+	if err := o.gormDB.WithContext(ctx).Where("published_at IS NULL").Order("id").Limit(1).Find(&oldest).Error; err != nil {
+		return nil, err
+	}
+
+	var deadLetters int64
+	if err := o.gormDB.WithContext(ctx).Model(&tables.DeadLetterEvents{}).Where("replayed_at IS NULL AND discarded_at IS NULL").Count(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &domain.OutboxStats{
+		Pending:            pending,
+		DeadLettersPending: deadLetters,
+	}
+	if len(oldest) > 0 {
+		stats.OldestPendingAt = &oldest[0].CreatedAt
+		stats.LagSeconds = time.Since(oldest[0].CreatedAt).Seconds()
+	}
+	return stats, nil
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+// publishedTypes relays one batch from outbox and returns the CloudEvents types published, failing every publish with err
+func publishedTypes(t *testing.T, outbox *repository.Outbox, err error) []string {
+	t.Helper()
+	var types []string
+	_, processErr := outbox.ProcessPending(context.Background(), 10, domain.RetryPolicy{MaxAttempts: 1}, func(message *domain.OutboxMessage) error {
+		if err != nil {
+			return err
+		}
+		var envelope domain.CloudEvent
+		if err := json.Unmarshal(message.Payload, &envelope); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		types = append(types, envelope.Type)
+		return nil
+	})
+	if processErr != nil {
+		t.Fatalf("ProcessPending: %v", processErr)
+	}
+	return types
+}
+
+func TestSQLiteOutboxHoldsBackDeadLetteredBooks(t *testing.T) {
+	ctx := context.Background()
+	gormDB := openSQLite(t)
+	repo := repository.NewBooksRepo(gormDB, nil, repository.CacheConfig{})
+	outbox := repository.NewOutboxRepo(gormDB)
+	deadLetters := repository.NewDeadLettersRepo(gormDB)
+
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	publishedTypes(t, outbox, errors.New("broker down"))
+	if err := repo.UpdateBookByID(ctx, book.ID, domain.Book{Year: 1951}); err != nil {
+		t.Fatalf("UpdateBookByID: %v", err)
+	}
+	if got := publishedTypes(t, outbox, nil); len(got) != 0 {
+		t.Fatalf("published %v while the creation was dead-lettered, want nothing", got)
+	}
+
+	dead, err := deadLetters.GetDeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetDeadLetters: %v", err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("GetDeadLetters returned %d dead letters, want 1", len(dead))
+	}
+	if err := deadLetters.ReplayDeadLetter(ctx, dead[0].ID); err != nil {
+		t.Fatalf("ReplayDeadLetter: %v", err)
+	}
+	if err := deadLetters.ReplayDeadLetter(ctx, dead[0].ID); !errors.Is(err, domain.ErrDeadLetterResolved) {
+		t.Fatalf("ReplayDeadLetter twice returned %v, want %v", err, domain.ErrDeadLetterResolved)
+	}
+	if err := deadLetters.DiscardDeadLetter(ctx, dead[0].ID); !errors.Is(err, domain.ErrDeadLetterResolved) {
+		t.Fatalf("DiscardDeadLetter after a replay returned %v, want %v", err, domain.ErrDeadLetterResolved)
+	}
+
+	want := []string{domain.BookCreatedEventType, domain.BookUpdatedEventType}
+	if got := publishedTypes(t, outbox, nil); !slices.Equal(got, want) {
+		t.Errorf("published %v after the replay, want %v", got, want)
+	}
+	stats, err := outbox.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 0 || stats.DeadLettersPending != 0 {
+		t.Errorf("Stats returned %d pending and %d dead letters pending, want none", stats.Pending, stats.DeadLettersPending)
+	}
+}
+
+// TestPostgresOutboxReleasesLockWhenUnlockFails cancels a relay run so its unlock fails, and checks
+// that another instance can still drain the outbox
+func TestPostgresOutboxReleasesLockWhenUnlockFails(t *testing.T) {
+	gormDB := openPostgres(t)
+	repo := repository.NewBooksRepo(gormDB, nil, repository.CacheConfig{})
+	servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	repository.NewOutboxRepo(gormDB).ProcessPending(ctx, 10, domain.RetryPolicy{MaxAttempts: 1}, func(*domain.OutboxMessage) error {
+		cancel()
+		return nil
+	})
+
+	other := connectPostgres(t, os.Getenv(postgresDSNEnv))
+	t.Cleanup(func() {
+		if sqlDB, err := other.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	want := []string{domain.BookCreatedEventType}
+	if got := publishedTypes(t, repository.NewOutboxRepo(other), nil); !slices.Equal(got, want) {
+		t.Errorf("another instance published %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores cached books. Get returns cache.ErrMiss when nothing is cached under the key.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	// Keys lists the keys matching a glob style pattern
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	Publish(ctx context.Context, channel string, message []byte) error
+	Subscribe(ctx context.Context, channel string, handle func(message []byte), resync func())
+}
+
+// localCache is the in-process tier kept in front of Cache
+type localCache interface {
+	Cache
+	Purge()
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/models/tables"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const BookSearchProjectionName = "book_search"
+
+type Checkpoints struct {
+	gormDB *gorm.DB
+}
+
+func NewCheckpointsRepo(gormDB *gorm.DB) *Checkpoints {
+	return &Checkpoints{
+		gormDB: gormDB,
+	}
+}
+
+func (c *Checkpoints) GetCheckpoint(ctx context.Context, projection, topic string, partition int) (int64, bool, error) {
+	var checkpoint tables.ProjectionCheckpoints
+	err := c.gormDB.WithContext(ctx).
+		Where("projection = ? AND topic = ? AND partition = ?", projection, topic, partition).
+		First(&checkpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return checkpoint.NextOffset, true, nil
+}
+
+func (c *Checkpoints) SaveCheckpoint(ctx context.Context, projection, topic string, partition int, nextOffset int64) error {
+	return c.gormDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "projection"}, {Name: "topic"}, {Name: "partition"}},
+		DoUpdates: clause.AssignmentColumns([]string{"next_offset", "updated_at"}),
+	}).Create(&tables.ProjectionCheckpoints{
+		Projection: projection,
+		Topic:      topic,
+		Partition:  partition,
+		NextOffset: nextOffset,
+	}).Error
+}
+
+func (c *Checkpoints) DeleteCheckpoints(ctx context.Context, projection string) error {
+	return c.gormDB.WithContext(ctx).Where("projection = ?", projection).Delete(&tables.ProjectionCheckpoints{}).Error
+}
+
+// BookSearch is a denormalized search table kept up to date from book events.
+// Deleted books are kept as tombstones, flagged deleted, which readers must filter out.
+type BookSearch struct {
+	gormDB *gorm.DB
+}
+
+func NewBookSearchProjection(gormDB *gorm.DB) *BookSearch {
+	return &BookSearch{
+		gormDB: gormDB,
+	}
+}
+
+func (b *BookSearch) Name() string {
+	return BookSearchProjectionName
+}
+
+func (b *BookSearch) Reset(ctx context.Context) error {
+	return b.gormDB.WithContext(ctx).Exec("TRUNCATE TABLE book_search").Error
+}
+
+// Apply upserts the book or marks it deleted. Rows are only overwritten by events at least as new
+// as the one that wrote them, and deleted books keep their row as a tombstone, so applying an event
+// twice or replaying an older one, even a creation after the deletion, is harmless.
+func (b *BookSearch) Apply(ctx context.Context, envelope *domain.CloudEvent, event domain.BookEvent) error {
+	switch e := event.(type) {
+	case domain.BookCreated:
+		return b.upsert(ctx, envelope, e.After)
+	case domain.BookUpdated:
+		return b.upsert(ctx, envelope, e.After)
+	case domain.BookDeleted:
+		return b.remove(ctx, envelope, e.Before.ID)
+	case domain.BookMerged:
+		if err := b.remove(ctx, envelope, e.Source.ID); err != nil {
+			return err
+		}
+		return b.upsert(ctx, envelope, e.After)
+	}
+	return nil
+}
+
+// remove writes a tombstone for the book, even when no row exists yet
+func (b *BookSearch) remove(ctx context.Context, envelope *domain.CloudEvent, bookID int) error {
+	return b.gormDB.WithContext(ctx).Clauses(newerEvent("deleted", "last_event_id", "last_event_time")).Create(&tables.BookSearch{
+		BookID:        bookID,
+		Deleted:       true,
+		LastEventID:   envelope.ID,
+		LastEventTime: envelope.Time,
+	}).Error
+}
+
+func (b *BookSearch) upsert(ctx context.Context, envelope *domain.CloudEvent, book domain.Book) error {
+	return b.gormDB.WithContext(ctx).Clauses(newerEvent("title", "author", "year", "search_text", "deleted", "last_event_id", "last_event_time")).Create(&tables.BookSearch{
+		BookID:        book.ID,
+		Title:         book.Title,
+		Author:        book.Author,
+		Year:          book.Year,
+		SearchText:    strings.ToLower(book.Title + " " + book.Author),
+		LastEventID:   envelope.ID,
+		LastEventTime: envelope.Time,
+	}).Error
+}
+
+// newerEvent overwrites columns of an existing row only with an event at least as new as its last one
+func newerEvent(columns ...string) clause.OnConflict {
+	return clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "book_search.last_event_time <= EXCLUDED.last_event_time"},
+		}},
+	}
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+const (
+	bookSearchCacheFormat = bookListCacheKey + ":v%d:search" // Under the list namespace version, since any write can change the results
+
+	// authorSearchWeight is what a word matching the author counts for, relative to the title,
+	// like the default weights ts_rank gives the B and A labels of search_vector
+	authorSearchWeight = 0.4
+)
+
+// bookSearchSQL ranks the books whose search_vector, the weighted title and author, matches the
+// websearch_to_tsquery of the text. Only the page is highlighted, since ts_headline reparses each text.
+const bookSearchSQL = `
+	SELECT id, title, author, year, rank,
+		ts_headline('english', replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), query,
+			'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS title_highlight,
+		ts_headline('english', replace(replace(replace(author, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), query,
+			'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS author_highlight
+	FROM (
+		SELECT id, title, author, year, ts_rank(search_vector, query) AS rank, query
+		FROM books, websearch_to_tsquery('english', ?) query
+		WHERE deleted_at IS NULL AND search_vector @@ query %s
+		ORDER BY %s
+		LIMIT ?
+	) page
+	ORDER BY %[2]s`
+
+// SearchBooks returns the page of books matching the full-text search, most relevant first.
+// Without Postgres, as on SQLite, every book is searched in process instead.
+func (b *Books) SearchBooks(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchList, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.loadSearch(ctx, query)
+	}
+	return cached(ctx, b, searchCacheKey(version, query), func(ctx context.Context) (*domain.BookSearchList, error) {
+		return b.loadSearch(ctx, query)
+	})
+}
+
+func (b *Books) loadSearch(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchList, error) {
+	if !isPostgres(b.gormDB) {
+		books, err := b.allBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return searchBooks(books, query), nil
+	}
+
+	vars := []interface{}{query.Text}
+	keyset := ""
+	order := "rank DESC, id"
+	before := query.Cursor != nil && query.Cursor.Before
+	if query.Cursor != nil {
+		keyset = "AND (ts_rank(search_vector, query) < ?::real OR (ts_rank(search_vector, query) = ?::real AND id > ?))"
+		if before {
+			// A page before the cursor is read backwards, starting from the cursor
+			keyset = "AND (ts_rank(search_vector, query) > ?::real OR (ts_rank(search_vector, query) = ?::real AND id < ?))"
+			order = "rank, id DESC"
+		}
+		vars = append(vars, query.Cursor.Rank, query.Cursor.Rank, query.Cursor.ID)
+	}
+	// One more result than asked for tells whether another page follows
+	vars = append(vars, query.Limit+1)
+
+	var hits []*domain.BookSearchHit
+	if err := b.gormDB.WithContext(ctx).Raw(fmt.Sprintf(bookSearchSQL, keyset, order), vars...).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	list := &domain.BookSearchList{}
+	list.Hits, list.More = pageOf(hits, query.Limit, before)
+	return list, nil
+}
+
+// CountSearchResults counts the books matching the full-text search
+func (b *Books) CountSearchResults(ctx context.Context, query domain.BookSearchQuery) (int64, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.countSearch(ctx, query)
+	}
+	return cached(ctx, b, fmt.Sprintf(bookSearchCacheFormat+":count:%s", version, url.Values{"q": {query.Text}}.Encode()), func(ctx context.Context) (int64, error) {
+		return b.countSearch(ctx, query)
+	})
+}
+
+func (b *Books) countSearch(ctx context.Context, query domain.BookSearchQuery) (int64, error) {
+	if !isPostgres(b.gormDB) {
+		books, err := b.allBooks(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return countSearchMatches(books, query), nil
+	}
+
+	var count int64
+	err := b.gormDB.WithContext(ctx).Raw(`
+		SELECT count(*) FROM books, websearch_to_tsquery('english', ?) query
+		WHERE deleted_at IS NULL AND search_vector @@ query`, query.Text).
+		Scan(&count).Error
+	return count, err
+}
+
+// searchCacheKey returns the key of a page of search results
+func searchCacheKey(version int64, query domain.BookSearchQuery) string {
+	params := url.Values{"q": {query.Text}}
+	if query.Cursor != nil {
+		direction := "after"
+		if query.Cursor.Before {
+			direction = "before"
+		}
+		params.Set(direction, strconv.FormatFloat(query.Cursor.Rank, 'g', -1, 64)+","+strconv.Itoa(query.Cursor.ID))
+	}
+	return fmt.Sprintf(bookSearchCacheFormat+":%d:%s", version, query.Limit, params.Encode())
+}
+
+// searchBooks approximates the Postgres full-text search in process: every word of the search must be
+// a word of the title or the author, and words in the title count more. There is no stemming and
+// there are no stop words.
+func searchBooks(books []*domain.Book, query domain.BookSearchQuery) *domain.BookSearchList {
+	terms := searchWords(query.Text)
+	before := query.Cursor != nil && query.Cursor.Before
+	var hits []*domain.BookSearchHit
+	for _, book := range books {
+		rank, ok := searchRank(*book, terms)
+		if !ok {
+			continue
+		}
+		if query.Cursor != nil {
+			if c := query.Cursor.Compare(rank, book.ID); (before && c >= 0) || (!before && c <= 0) {
+				continue
+			}
+		}
+		hits = append(hits, &domain.BookSearchHit{
+			Book:            *book,
+			Rank:            rank,
+			TitleHighlight:  highlight(book.Title, terms),
+			AuthorHighlight: highlight(book.Author, terms),
+		})
+	}
+
+	// Sorted in the direction of travel, starting from the cursor
+	slices.SortFunc(hits, func(a, b *domain.BookSearchHit) int {
+		c := domain.BookSearchCursor{Rank: b.Rank, ID: b.ID}.Compare(a.Rank, a.ID)
+		if before {
+			return -c
+		}
+		return c
+	})
+	if len(hits) > query.Limit+1 {
+		hits = hits[:query.Limit+1]
+	}
+	list := &domain.BookSearchList{}
+	list.Hits, list.More = pageOf(hits, query.Limit, before)
+	return list
+}
+
+func countSearchMatches(books []*domain.Book, query domain.BookSearchQuery) int64 {
+	terms := searchWords(query.Text)
+	var count int64
+	for _, book := range books {
+		if _, ok := searchRank(*book, terms); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// searchRank scores book for the search terms, or reports false when a term matches neither its title nor its author
+func searchRank(book domain.Book, terms []string) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, false
+	}
+	titleWords, authorWords := searchWords(book.Title), searchWords(book.Author)
+	var score float64
+	for _, term := range terms {
+		switch {
+		case slices.Contains(titleWords, term):
+			score++
+		case slices.Contains(authorWords, term):
+			score += authorSearchWeight
+		default:
+			return 0, false
+		}
+	}
+	return score / float64(len(terms)), true
+}
+
+// searchWords splits s into its normalized words
+func searchWords(s string) []string {
+	return strings.FieldsFunc(domain.NormalizeText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlight HTML escapes text and wraps the words that are search terms in <mark> elements, like ts_headline
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	writeWord := func(word string) {
+		if !slices.Contains(terms, domain.NormalizeText(word)) {
+			b.WriteString(html.EscapeString(word))
+			return
+		}
+		b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+	}
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			writeWord(text[start:i])
+			start = -1
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if start >= 0 {
+		writeWord(text[start:])
+	}
+	return b.String()
+}
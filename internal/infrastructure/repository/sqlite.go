@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/Redarcher9/Books-Management-System/db"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// OpenSQLite opens the SQLite database at path, or a private in-memory database for ":memory:",
+// and creates its tables. The Books, Outbox and DeadLetters repositories work on it like on Postgres.
+func OpenSQLite(path string) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", path)
+	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		// Report constraint violations as gorm errors such as gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, and every connection to ":memory:" would open a different database
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := gormDB.Exec(db.SQLiteSchema).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+	return gormDB, nil
+}
+
+// isPostgres reports whether gormDB is a Postgres database, whose extensions and locks SQLite lacks
+func isPostgres(gormDB *gorm.DB) bool {
+	return gormDB.Dialector.Name() == "postgres"
+}
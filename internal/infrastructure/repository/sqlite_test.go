@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/cache"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+	"gorm.io/gorm"
+)
+
+func TestSQLiteBooks(t *testing.T) {
+	servicetest.TestBookRepo(t, func(t *testing.T) service.BookRepo {
+		return repository.NewBooksRepo(openSQLite(t), nil, repository.CacheConfig{})
+	})
+}
+
+func TestSQLiteBooksCached(t *testing.T) {
+	servicetest.TestBookRepo(t, func(t *testing.T) service.BookRepo {
+		return repository.NewBooksRepo(openSQLite(t), cache.NewMemory(0), repository.CacheConfig{LocalSize: 100})
+	})
+}
+
+// openSQLite returns a new in-memory SQLite database, closed when the test ends
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := repository.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return gormDB
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+const (
+	bookSuggestCacheFormat = bookListCacheKey + ":v%d:suggest" // Under the list namespace version, since any write can change the suggestions
+
+	// suggestWordThreshold is the default pg_trgm.word_similarity_threshold used by the <% operator
+	suggestWordThreshold = 0.6
+)
+
+// bookSuggestSQL completes the prefix with the distinct normalized titles and authors that have a word
+// starting with it, then with those only similar to it, so typos still find them. Both run on the trigram indexes.
+const bookSuggestSQL = `
+	SELECT field, text, books FROM (
+		SELECT 'title' AS field, min(title) AS text, count(*) AS books, title_normalized AS normalized,
+			(title_normalized LIKE @start ESCAPE '\' OR title_normalized LIKE @word ESCAPE '\') AS prefixed,
+			word_similarity(@prefix, title_normalized) AS similarity
+		FROM books
+		WHERE deleted_at IS NULL AND
+			(title_normalized LIKE @start ESCAPE '\' OR title_normalized LIKE @word ESCAPE '\' OR @prefix <% title_normalized)
+		GROUP BY title_normalized
+		UNION ALL
+		SELECT 'author', min(author), count(*), author_normalized,
+			(author_normalized LIKE @start ESCAPE '\' OR author_normalized LIKE @word ESCAPE '\'),
+			word_similarity(@prefix, author_normalized)
+		FROM books
+		WHERE deleted_at IS NULL AND
+			(author_normalized LIKE @start ESCAPE '\' OR author_normalized LIKE @word ESCAPE '\' OR @prefix <% author_normalized)
+		GROUP BY author_normalized
+	) suggestions
+	ORDER BY prefixed DESC, similarity DESC, books DESC, normalized, field DESC
+	LIMIT @limit`
+
+// SuggestBooks returns the titles and authors completing the prefix, those with a word starting with it first,
+// then the most similar. Without pg_trgm, as on SQLite, every title and author is compared in process instead.
+func (b *Books) SuggestBooks(ctx context.Context, query domain.BookSuggestQuery) ([]*domain.BookSuggestion, error) {
+	version, ok := b.listCacheVersion(ctx)
+	if !ok {
+		domain.RecordCacheStatus(ctx, domain.CacheMiss)
+		return b.loadSuggestions(ctx, query)
+	}
+	key := fmt.Sprintf(bookSuggestCacheFormat+":%d:%s", version, query.Limit, url.Values{"prefix": {query.Prefix}}.Encode())
+	return cached(ctx, b, key, func(ctx context.Context) ([]*domain.BookSuggestion, error) {
+		return b.loadSuggestions(ctx, query)
+	})
+}
+
+func (b *Books) loadSuggestions(ctx context.Context, query domain.BookSuggestQuery) ([]*domain.BookSuggestion, error) {
+	if !isPostgres(b.gormDB) {
+		books, err := b.allBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return suggestBooks(books, query), nil
+	}
+
+	escaped := likeEscaper.Replace(query.Prefix)
+	var suggestions []*domain.BookSuggestion
+	err := b.gormDB.WithContext(ctx).Raw(bookSuggestSQL, map[string]interface{}{
+		"prefix": query.Prefix,
+		"start":  escaped + "%",
+		"word":   "% " + escaped + "%",
+		"limit":  query.Limit,
+	}).Scan(&suggestions).Error
+	return suggestions, err
+}
+
+// suggestion is a distinct normalized title or author being ranked as a completion
+type suggestion struct {
+	domain.BookSuggestion
+	normalized string
+	prefixed   bool
+	similarity float64
+}
+
+// suggestBooks ranks the distinct titles and authors of books like bookSuggestSQL
+func suggestBooks(books []*domain.Book, query domain.BookSuggestQuery) []*domain.BookSuggestion {
+	candidates := make(map[[2]string]*suggestion)
+	add := func(field, text string) {
+		normalized := domain.NormalizeText(text)
+		if c, ok := candidates[[2]string{field, normalized}]; ok {
+			c.Books++
+			c.Text = min(c.Text, text)
+			return
+		}
+		c := &suggestion{
+			BookSuggestion: domain.BookSuggestion{Field: field, Text: text, Books: 1},
+			normalized:     normalized,
+			prefixed:       strings.HasPrefix(normalized, query.Prefix) || strings.Contains(normalized, " "+query.Prefix),
+			similarity:     domain.WordSimilarity(query.Prefix, normalized),
+		}
+		if c.prefixed || c.similarity >= suggestWordThreshold {
+			candidates[[2]string{field, normalized}] = c
+		}
+	}
+	for _, book := range books {
+		add(domain.BookSuggestionTitle, book.Title)
+		add(domain.BookSuggestionAuthor, book.Author)
+	}
+
+	ranked := make([]*suggestion, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+	slices.SortFunc(ranked, func(a, b *suggestion) int {
+		if a.prefixed != b.prefixed {
+			if a.prefixed {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(b.similarity, a.similarity),
+			cmp.Compare(b.Books, a.Books),
+			strings.Compare(a.normalized, b.normalized),
+			strings.Compare(b.Field, a.Field),
+		)
+	})
+	if len(ranked) > query.Limit {
+		ranked = ranked[:query.Limit]
+	}
+
+	suggestions := make([]*domain.BookSuggestion, 0, len(ranked))
+	for _, c := range ranked {
+		suggestions = append(suggestions, &c.BookSuggestion)
+	}
+	return suggestions
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Redarcher9/Books-Management-System/internal/controller"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminRouter(group *gin.RouterGroup, outboxRelay *service.OutboxRelay, deadLetterService *service.DeadLetterInteractor, cacheService *service.CacheInteractor) {
+	outboxController := controller.NewOutboxController(outboxRelay)
+	deadLetterController := controller.NewDeadLetterController(deadLetterService)
+	cacheController := controller.NewCacheController(cacheService)
+
+	//Initialise Routes
+	group.GET("/outbox", outboxController.GetOutboxStats)
+	group.GET("/dead-letters", deadLetterController.GetDeadLetters)
+	group.GET("/dead-letters/:id", deadLetterController.GetDeadLetterByID)
+	group.POST("/dead-letters/:id/replay", deadLetterController.ReplayDeadLetter)
+	group.POST("/dead-letters/:id/discard", deadLetterController.DiscardDeadLetter)
+	group.GET("/cache", cacheController.GetCacheStats)
+	group.DELETE("/cache", cacheController.FlushCache)
+	group.GET("/cache/books/:id", cacheController.GetCachedBook)
+	group.POST("/cache/warm", cacheController.WarmCache)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeout cancels the context of every request after timeout; 0 disables the deadline
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		if timeout <= 0 {
+			g.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(g.Request.Context(), timeout)
+		defer cancel()
+		g.Request = g.Request.WithContext(ctx)
+		g.Next()
+	}
+}
@@ -2,18 +2,17 @@ package routes
 
 import (
 	docs "github.com/Redarcher9/Books-Management-System/docs"
-	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/kafka"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"gorm.io/gorm"
 )
 
-func SetupRoutes(gin *gin.Engine, gormDB *gorm.DB, kafka *kafka.KafkaProducer, redis *redis.Client) {
+func SetupRoutes(gin *gin.Engine, bookService *service.BookInteractor, outboxRelay *service.OutboxRelay, deadLetterService *service.DeadLetterInteractor, cacheService *service.CacheInteractor) {
 	// @BasePath /api/v1
 	Router := gin.Group("/api/v1")
-	NewBookRouter(Router, gormDB, kafka, redis)
+	NewBookRouter(Router, bookService)
+	NewAdminRouter(Router.Group("/admin"), outboxRelay, deadLetterService, cacheService)
 }
 
 func SetupSwagger(gin *gin.Engine) {
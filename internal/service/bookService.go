@@ -2,77 +2,204 @@ package service
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/Redarcher9/Books-Management-System/internal/domain"
 )
 
+const (
+	defaultDuplicateThreshold = 0.75
+	duplicateCandidateLimit   = 20 // Similar titles scored per new book
+)
+
 type BookInteractor struct {
-	Repo          BookRepo
-	KafkaProducer KafkaProducer
+	Repo               BookRepo
+	DuplicateThreshold float64 // Minimum domain.BookSimilarity for a book to be reported as a duplicate
 }
 
-// NewBookInteractor returns a valid book interactor
-func NewBookInteractor(repo BookRepo, KafkaProducer KafkaProducer) *BookInteractor {
+// NewBookInteractor returns a valid book interactor.
+// Book events are written to the outbox by the repository and published by the OutboxRelay.
+func NewBookInteractor(repo BookRepo, duplicateThreshold float64) *BookInteractor {
 	if repo == nil {
 		return nil
 	}
+	if duplicateThreshold <= 0 {
+		duplicateThreshold = defaultDuplicateThreshold
+	}
 	return &BookInteractor{
-		Repo:          repo,
-		KafkaProducer: KafkaProducer,
+		Repo:               repo,
+		DuplicateThreshold: duplicateThreshold,
 	}
 }
 
-func (c BookInteractor) GetBooks(ctx context.Context, offset, limit int) ([]*domain.Book, error) {
-	return c.Repo.GetBooks(ctx, offset, limit)
+// GetBooks returns a page of the books matching query, with cursors to the pages around it.
+// The query is normalized so that equal queries share cache entries and cursors.
+func (c BookInteractor) GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookPage, error) {
+	query = query.Normalize()
+	list, err := c.Repo.GetBooks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.BookPage{Books: list.Books}
+	if page.Books == nil {
+		page.Books = []*domain.Book{}
+	}
+	if len(list.Books) > 0 {
+		prev, next := pageCursors(query.Cursor != nil, query.Cursor != nil && query.Cursor.Before, list.More)
+		if prev {
+			page.PrevCursor = domain.EncodeBookCursor(query, domain.BookCursor{Book: *list.Books[0], Before: true})
+		}
+		if next {
+			page.NextCursor = domain.EncodeBookCursor(query, domain.BookCursor{Book: *list.Books[len(list.Books)-1]})
+		}
+	}
+
+	if query.Total {
+		total, err := c.Repo.CountBooks(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+	return page, nil
 }
 
-func (c BookInteractor) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
-	return c.Repo.GetBookByID(ctx, ID)
+// GetBookFacets counts the books matching the filters of query by author, up to authors of them, and by decade and year
+func (c BookInteractor) GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error) {
+	return c.Repo.GetBookFacets(ctx, query.Normalize(), authors)
 }
 
-func (c BookInteractor) DeleteBookByID(ctx context.Context, ID int) error {
-	err := c.Repo.DeleteBookByID(ctx, ID)
+// SearchBooks returns a page of the books matching the full-text search, most relevant first,
+// with cursors to the pages around it
+func (c BookInteractor) SearchBooks(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchPage, error) {
+	searchRepo, ok := c.Repo.(BookSearchRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	query = query.Normalize()
+	list, err := searchRepo.SearchBooks(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	page := &domain.BookSearchPage{Books: list.Hits}
+	if page.Books == nil {
+		page.Books = []*domain.BookSearchHit{}
+	}
+	if len(list.Hits) > 0 {
+		prev, next := pageCursors(query.Cursor != nil, query.Cursor != nil && query.Cursor.Before, list.More)
+		if prev {
+			first := list.Hits[0]
+			page.PrevCursor = domain.EncodeBookSearchCursor(query, domain.BookSearchCursor{Rank: first.Rank, ID: first.ID, Before: true})
+		}
+		if next {
+			last := list.Hits[len(list.Hits)-1]
+			page.NextCursor = domain.EncodeBookSearchCursor(query, domain.BookSearchCursor{Rank: last.Rank, ID: last.ID})
+		}
 	}
-	message := map[string]interface{}{
-		"event": "DELETE",
-		"ID":    ID,
+
+	if query.Total {
+		total, err := searchRepo.CountSearchResults(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
 	}
-	//Publish kafka message
-	c.KafkaProducer.Publish(ctx, "book_events", message)
-	return nil
+	return page, nil
 }
 
-func (c BookInteractor) UpdateBookByID(ctx context.Context, ID int, book domain.Book) error {
-	err := c.Repo.UpdateBookByID(ctx, ID, book)
+// SuggestBooks returns the titles and authors completing the prefix, best completion first
+func (c BookInteractor) SuggestBooks(ctx context.Context, query domain.BookSuggestQuery) (*domain.BookSuggestions, error) {
+	suggestRepo, ok := c.Repo.(BookSuggestRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	suggestions, err := suggestRepo.SuggestBooks(ctx, query.Normalize())
 	if err != nil {
-		return err
-	}
-	message := map[string]interface{}{
-		"event":  "UPDATE",
-		"ID":     ID,
-		"TITLE":  book.Title,
-		"AUTHOR": book.Author,
-		"YEAR":   book.Year,
-	}
-	//Publish kafka message
-	c.KafkaProducer.Publish(ctx, "book_events", message)
-	return nil
+		return nil, err
+	}
+	if suggestions == nil {
+		suggestions = []*domain.BookSuggestion{}
+	}
+	return &domain.BookSuggestions{Suggestions: suggestions}, nil
+}
+
+// pageCursors reports which cursors a non-empty page has. A page read forwards from a cursor
+// has results before it, and a page read backwards has results after it.
+func pageCursors(fromCursor, before, more bool) (prev, next bool) {
+	return (before && more) || (!before && fromCursor), (!before && more) || before
+}
+
+func (c BookInteractor) GetBookByID(ctx context.Context, ID int) (*domain.Book, error) {
+	return c.Repo.GetBookByID(ctx, ID)
+}
+
+// GetBookAt returns the book as it was at the given time, when the repository keeps history
+func (c BookInteractor) GetBookAt(ctx context.Context, ID int, at time.Time) (*domain.Book, error) {
+	historyRepo, ok := c.Repo.(BookHistoryRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return historyRepo.GetBookAt(ctx, ID, at)
+}
+
+func (c BookInteractor) DeleteBookByID(ctx context.Context, ID int) error {
+	return c.Repo.DeleteBookByID(ctx, ID)
 }
 
-func (c BookInteractor) CreateBook(ctx context.Context, book *domain.Book) error {
-	err := c.Repo.CreateBook(ctx, book)
+func (c BookInteractor) UpdateBookByID(ctx context.Context, ID int, book domain.Book) error {
+	return c.Repo.UpdateBookByID(ctx, ID, book)
+}
+
+// FindDuplicates returns the existing books that are likely the same book as book, best match first.
+// Repositories that can not search by similarity never report duplicates.
+func (c BookInteractor) FindDuplicates(ctx context.Context, book domain.Book) ([]domain.DuplicateCandidate, error) {
+	similarityRepo, ok := c.Repo.(BookSimilarityRepo)
+	if !ok {
+		return nil, nil
+	}
+	books, err := similarityRepo.FindSimilarBooks(ctx, book.Title, duplicateCandidateLimit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	message := map[string]interface{}{
-		"event":  "CREATE",
-		"TITLE":  book.Title,
-		"AUTHOR": book.Author,
-		"YEAR":   book.Year,
+
+	var candidates []domain.DuplicateCandidate
+	for _, existing := range books {
+		if score := domain.BookSimilarity(book, *existing); score >= c.DuplicateThreshold {
+			candidates = append(candidates, domain.DuplicateCandidate{Book: *existing, Score: score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// MergeBooks merges the book request.SourceID into the book with ID targetID and returns the merged book
+func (c BookInteractor) MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error) {
+	if err := request.Validate(targetID); err != nil {
+		return nil, err
+	}
+	mergeRepo, ok := c.Repo.(BookMergeRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return mergeRepo.MergeBooks(ctx, targetID, request)
+}
+
+// CreateBook creates book. Unless force is set, a book that looks like existing books is rejected
+// with a *domain.DuplicateCandidatesError listing them.
+func (c BookInteractor) CreateBook(ctx context.Context, book *domain.Book, force bool) error {
+	if !force {
+		candidates, err := c.FindDuplicates(ctx, *book)
+		if err != nil {
+			return err
+		}
+		if len(candidates) > 0 {
+			return &domain.DuplicateCandidatesError{Candidates: candidates}
+		}
 	}
-	//Publish kafka message
-	c.KafkaProducer.Publish(ctx, "book_events", message)
-	return nil
+	return c.Repo.CreateBook(ctx, book)
 }
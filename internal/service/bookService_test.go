@@ -0,0 +1,330 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+// basicRepo hides the optional capabilities of the repository it wraps
+type basicRepo struct {
+	service.BookRepo
+}
+
+func TestFindDuplicates(t *testing.T) {
+	repo := repository.NewMemoryBooksRepo(nil)
+	hobbit := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	servicetest.CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+
+	tests := []struct {
+		name      string
+		repo      service.BookRepo
+		threshold float64
+		book      domain.Book
+		want      []int
+	}{
+		{name: "reordered title and author", repo: repo, book: domain.Book{Title: "Hobbit, The", Author: "Tolkien, J. R. R."}, want: []int{hobbit.ID}},
+		{name: "other author below the default threshold", repo: repo, book: domain.Book{Title: "The Hobbit", Author: "Christopher Tolkien"}},
+		{name: "other author above a lower threshold", repo: repo, threshold: 0.6, book: domain.Book{Title: "The Hobbit", Author: "Christopher Tolkien"}, want: []int{hobbit.ID}},
+		{name: "unrelated book", repo: repo, book: domain.Book{Title: "Emma", Author: "Jane Austen"}},
+		{name: "repository without similarity search", repo: basicRepo{repo}, book: domain.Book{Title: "The Hobbit", Author: "J.R.R. Tolkien"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor := service.NewBookInteractor(tt.repo, tt.threshold)
+			candidates, err := interactor.FindDuplicates(context.Background(), tt.book)
+			if err != nil {
+				t.Fatalf("FindDuplicates: %v", err)
+			}
+			if len(candidates) != len(tt.want) {
+				t.Fatalf("FindDuplicates returned %v, want books %v", candidates, tt.want)
+			}
+			for i, candidate := range candidates {
+				if candidate.Book.ID != tt.want[i] {
+					t.Errorf("candidate %d is book %d, want %d", i, candidate.Book.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeBooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		basic   bool
+		request func(target, source domain.Book) domain.BookMergeRequest
+		wantErr error
+	}{
+		{
+			name: "merge",
+			request: func(target, source domain.Book) domain.BookMergeRequest {
+				return domain.BookMergeRequest{SourceID: source.ID}
+			},
+		},
+		{
+			name: "into itself",
+			request: func(target, source domain.Book) domain.BookMergeRequest {
+				return domain.BookMergeRequest{SourceID: target.ID}
+			},
+			wantErr: domain.ErrInvalidMerge,
+		},
+		{
+			name: "unknown field choice",
+			request: func(target, source domain.Book) domain.BookMergeRequest {
+				return domain.BookMergeRequest{SourceID: source.ID, Title: "both"}
+			},
+			wantErr: domain.ErrInvalidMerge,
+		},
+		{
+			name:    "invalid request to a repository without merging",
+			basic:   true,
+			request: func(target, source domain.Book) domain.BookMergeRequest { return domain.BookMergeRequest{} },
+			wantErr: domain.ErrInvalidMerge,
+		},
+		{
+			name:  "repository without merging",
+			basic: true,
+			request: func(target, source domain.Book) domain.BookMergeRequest {
+				return domain.BookMergeRequest{SourceID: source.ID}
+			},
+			wantErr: domain.ErrNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repo service.BookRepo = repository.NewMemoryBooksRepo(nil)
+			target := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+			source := servicetest.CreateBook(t, repo, "Hobbit, The", "Tolkien", 1951)
+			if tt.basic {
+				repo = basicRepo{repo}
+			}
+
+			merged, err := service.NewBookInteractor(repo, 0).MergeBooks(context.Background(), target.ID, tt.request(target, source))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeBooks returned %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && *merged != target {
+				t.Errorf("MergeBooks returned %+v, want %+v", *merged, target)
+			}
+		})
+	}
+}
+
+func TestGetBookAtNotSupported(t *testing.T) {
+	interactor := service.NewBookInteractor(repository.NewMemoryBooksRepo(nil), 0)
+	if _, err := interactor.GetBookAt(context.Background(), 1, time.Now()); !errors.Is(err, domain.ErrNotSupported) {
+		t.Errorf("GetBookAt returned %v, want domain.ErrNotSupported", err)
+	}
+}
+
+func TestGetBooksPages(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryBooksRepo(nil)
+	var books []domain.Book
+	for i, title := range []string{"Beloved", "Dune", "Emma", "The Hobbit", "Ulysses"} {
+		books = append(books, servicetest.CreateBook(t, repo, title, "Author", 1900+i))
+	}
+	interactor := service.NewBookInteractor(repo, 0)
+
+	// Forwards through every page and back again
+	steps := []struct {
+		name     string
+		follow   func(page *domain.BookPage) string
+		want     []domain.Book
+		wantPrev bool
+		wantNext bool
+	}{
+		{name: "first page", want: books[0:2], wantNext: true},
+		{name: "second page", follow: nextCursor, want: books[2:4], wantPrev: true, wantNext: true},
+		{name: "last page", follow: nextCursor, want: books[4:], wantPrev: true},
+		{name: "back to the second page", follow: prevCursor, want: books[2:4], wantPrev: true, wantNext: true},
+		{name: "back to the first page", follow: prevCursor, want: books[0:2], wantNext: true},
+	}
+	var page *domain.BookPage
+	for _, step := range steps {
+		query := domain.BookQuery{Limit: 2}
+		if step.follow != nil {
+			cursor, err := domain.DecodeBookCursor(step.follow(page), query)
+			if err != nil {
+				t.Fatalf("%s: DecodeBookCursor: %v", step.name, err)
+			}
+			query.Cursor = cursor
+		}
+
+		var err error
+		page, err = interactor.GetBooks(ctx, query)
+		if err != nil {
+			t.Fatalf("%s: GetBooks: %v", step.name, err)
+		}
+		if len(page.Books) != len(step.want) {
+			t.Fatalf("%s: got %d books, want %d", step.name, len(page.Books), len(step.want))
+		}
+		for i := range step.want {
+			if *page.Books[i] != step.want[i] {
+				t.Errorf("%s: book %d = %+v, want %+v", step.name, i, *page.Books[i], step.want[i])
+			}
+		}
+		if (page.PrevCursor != "") != step.wantPrev || (page.NextCursor != "") != step.wantNext {
+			t.Errorf("%s: prev cursor %q and next cursor %q, want prev %t and next %t", step.name, page.PrevCursor, page.NextCursor, step.wantPrev, step.wantNext)
+		}
+		if page.Total != nil {
+			t.Errorf("%s: total %d was not asked for", step.name, *page.Total)
+		}
+	}
+
+	// A cursor only fits the filters and sort it was made for
+	if _, err := domain.DecodeBookCursor(page.NextCursor, domain.BookQuery{Sort: domain.ParseBookSort("-year")}); !errors.Is(err, domain.ErrInvalidBookQuery) {
+		t.Errorf("DecodeBookCursor with another sort returned %v, want domain.ErrInvalidBookQuery", err)
+	}
+
+	page, err := interactor.GetBooks(ctx, domain.BookQuery{Title: "missing", Limit: 2, Total: true})
+	if err != nil {
+		t.Fatalf("GetBooks: %v", err)
+	}
+	if page.Books == nil || len(page.Books) != 0 || page.PrevCursor != "" || page.NextCursor != "" {
+		t.Errorf("GetBooks returned %+v, want an empty page without cursors", page)
+	}
+	if page.Total == nil || *page.Total != 0 {
+		t.Errorf("GetBooks returned total %v, want 0", page.Total)
+	}
+}
+
+func nextCursor(page *domain.BookPage) string { return page.NextCursor }
+
+func prevCursor(page *domain.BookPage) string { return page.PrevCursor }
+
+func TestSearchBooks(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryBooksRepo(nil)
+	hobbit := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	silmarillion := servicetest.CreateBook(t, repo, "The Silmarillion", "J.R.R. Tolkien", 1977)
+	biography := servicetest.CreateBook(t, repo, "Tolkien: A Biography", "Humphrey Carpenter", 1977)
+	interactor := service.NewBookInteractor(repo, 0)
+
+	// Forwards through every page and back again, with the search text spelled differently each time
+	steps := []struct {
+		name     string
+		text     string
+		follow   func(page *domain.BookSearchPage) string
+		want     []domain.Book
+		wantPrev bool
+		wantNext bool
+	}{
+		{name: "first page", text: "Tolkien", want: []domain.Book{biography, hobbit}, wantNext: true},
+		{name: "last page", text: "tolkien", follow: func(page *domain.BookSearchPage) string { return page.NextCursor }, want: []domain.Book{silmarillion}, wantPrev: true},
+		{name: "back to the first page", text: " TOLKIEN", follow: func(page *domain.BookSearchPage) string { return page.PrevCursor }, want: []domain.Book{biography, hobbit}, wantNext: true},
+	}
+	var page *domain.BookSearchPage
+	for _, step := range steps {
+		query := domain.BookSearchQuery{Text: step.text, Limit: 2, Total: true}
+		if step.follow != nil {
+			cursor, err := domain.DecodeBookSearchCursor(step.follow(page), query)
+			if err != nil {
+				t.Fatalf("%s: DecodeBookSearchCursor: %v", step.name, err)
+			}
+			query.Cursor = cursor
+		}
+
+		var err error
+		page, err = interactor.SearchBooks(ctx, query)
+		if err != nil {
+			t.Fatalf("%s: SearchBooks: %v", step.name, err)
+		}
+		if len(page.Books) != len(step.want) {
+			t.Fatalf("%s: got %d books, want %d", step.name, len(page.Books), len(step.want))
+		}
+		for i := range step.want {
+			if page.Books[i].Book != step.want[i] {
+				t.Errorf("%s: book %d = %+v, want %+v", step.name, i, page.Books[i].Book, step.want[i])
+			}
+		}
+		if (page.PrevCursor != "") != step.wantPrev || (page.NextCursor != "") != step.wantNext {
+			t.Errorf("%s: prev cursor %q and next cursor %q, want prev %t and next %t", step.name, page.PrevCursor, page.NextCursor, step.wantPrev, step.wantNext)
+		}
+		if page.Total == nil || *page.Total != 3 {
+			t.Errorf("%s: total %v, want 3", step.name, page.Total)
+		}
+	}
+
+	// A cursor only fits the search it was made for
+	if _, err := domain.DecodeBookSearchCursor(page.NextCursor, domain.BookSearchQuery{Text: "hobbit"}); !errors.Is(err, domain.ErrInvalidBookQuery) {
+		t.Errorf("DecodeBookSearchCursor with another search returned %v, want domain.ErrInvalidBookQuery", err)
+	}
+
+	if _, err := service.NewBookInteractor(basicRepo{repo}, 0).SearchBooks(ctx, domain.BookSearchQuery{Text: "tolkien", Limit: 2}); !errors.Is(err, domain.ErrNotSupported) {
+		t.Errorf("SearchBooks without a search repository returned %v, want domain.ErrNotSupported", err)
+	}
+}
+
+func TestSuggestBooks(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryBooksRepo(nil)
+	for i := 0; i < 3; i++ {
+		servicetest.CreateBook(t, repo, fmt.Sprintf("Dune %d", i), "Frank Herbert", 1965+i)
+	}
+
+	tests := []struct {
+		name      string
+		repo      service.BookRepo
+		query     domain.BookSuggestQuery
+		wantCount int
+		wantErr   error
+	}{
+		{name: "limit", repo: repo, query: domain.BookSuggestQuery{Prefix: "dune", Limit: 2}, wantCount: 2},
+		{name: "limit above the maximum", repo: repo, query: domain.BookSuggestQuery{Prefix: "d", Limit: 1000}, wantCount: 3},
+		{name: "no suggestions", repo: repo, query: domain.BookSuggestQuery{Prefix: "austen", Limit: 5}},
+		{name: "repository without suggestions", repo: basicRepo{repo}, query: domain.BookSuggestQuery{Prefix: "dune", Limit: 5}, wantErr: domain.ErrNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.NewBookInteractor(tt.repo, 0).SuggestBooks(ctx, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SuggestBooks(%+v) returned %v, want %v", tt.query, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Suggestions == nil || len(got.Suggestions) != tt.wantCount {
+				t.Errorf("SuggestBooks(%+v) returned %v, want %d suggestions", tt.query, got.Suggestions, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestGetBookFacets(t *testing.T) {
+	repo := repository.NewMemoryBooksRepo(nil)
+	for i := 0; i < 3; i++ {
+		servicetest.CreateBook(t, repo, "Dune", fmt.Sprintf("Author %d", i), 1965+i)
+	}
+	interactor := service.NewBookInteractor(repo, 0)
+
+	tests := []struct {
+		name        string
+		authors     int
+		wantAuthors int
+	}{
+		{name: "authors", authors: 2, wantAuthors: 2},
+		{name: "more authors than there are", authors: domain.MaxFacetAuthors, wantAuthors: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facets, err := interactor.GetBookFacets(context.Background(), domain.BookQuery{Title: " DUNE"}, tt.authors)
+			if err != nil {
+				t.Fatalf("GetBookFacets: %v", err)
+			}
+			if len(facets.Authors) != tt.wantAuthors {
+				t.Errorf("GetBookFacets counted %d authors %v, want %d", len(facets.Authors), facets.Authors, tt.wantAuthors)
+			}
+			if facets.Total != 3 {
+				t.Errorf("GetBookFacets total = %d, want 3", facets.Total)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+type CacheInteractor struct {
+	Repo BookRepo
+}
+
+// NewCacheInteractor returns a valid cache interactor.
+// Every call returns domain.ErrNotSupported when the book repository has no cache.
+func NewCacheInteractor(repo BookRepo) *CacheInteractor {
+	if repo == nil {
+		return nil
+	}
+	return &CacheInteractor{
+		Repo: repo,
+	}
+}
+
+func (c CacheInteractor) GetCacheStats(ctx context.Context) (*domain.CacheStats, error) {
+	cacheRepo, ok := c.Repo.(BookCacheRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return cacheRepo.CacheStats(ctx)
+}
+
+func (c CacheInteractor) GetCachedBook(ctx context.Context, ID int) (*domain.CachedBook, error) {
+	cacheRepo, ok := c.Repo.(BookCacheRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return cacheRepo.CachedBook(ctx, ID)
+}
+
+func (c CacheInteractor) WarmCache(ctx context.Context, count, pageSize int) (*domain.CacheWarmResult, error) {
+	cacheRepo, ok := c.Repo.(BookCacheRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return cacheRepo.WarmCache(ctx, count, pageSize)
+}
+
+func (c CacheInteractor) FlushCache(ctx context.Context) (*domain.CacheFlushResult, error) {
+	cacheRepo, ok := c.Repo.(BookCacheRepo)
+	if !ok {
+		return nil, domain.ErrNotSupported
+	}
+	return cacheRepo.FlushCache(ctx)
+}
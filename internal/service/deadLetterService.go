@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+type DeadLetterInteractor struct {
+	Repo DeadLetterRepo
+}
+
+// NewDeadLetterInteractor returns a valid dead letter interactor
+func NewDeadLetterInteractor(repo DeadLetterRepo) *DeadLetterInteractor {
+	if repo == nil {
+		return nil
+	}
+	return &DeadLetterInteractor{
+		Repo: repo,
+	}
+}
+
+func (c DeadLetterInteractor) GetDeadLetters(ctx context.Context, offset, limit int) ([]*domain.DeadLetter, error) {
+	return c.Repo.GetDeadLetters(ctx, offset, limit)
+}
+
+func (c DeadLetterInteractor) GetDeadLetterByID(ctx context.Context, ID int64) (*domain.DeadLetter, error) {
+	return c.Repo.GetDeadLetterByID(ctx, ID)
+}
+
+// ReplayDeadLetter re-enqueues the event in the outbox; the relay publishes it on its next run
+func (c DeadLetterInteractor) ReplayDeadLetter(ctx context.Context, ID int64) error {
+	return c.Repo.ReplayDeadLetter(ctx, ID)
+}
+
+// DiscardDeadLetter drops the event for good; the relay then publishes the book's later events
+func (c DeadLetterInteractor) DiscardDeadLetter(ctx context.Context, ID int64) error {
+	return c.Repo.DiscardDeadLetter(ctx, ID)
+}
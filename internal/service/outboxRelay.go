@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+// OutboxRelay drains the outbox to the event bus.
+// Messages are only marked published once Publish succeeds, so delivery is at-least-once.
+// Failed messages are retried according to RetryPolicy and then dead-lettered.
+type OutboxRelay struct {
+	Repo         OutboxRepo
+	EventBus     EventBus
+	PollInterval time.Duration
+	BatchSize    int
+	RetryPolicy  domain.RetryPolicy
+
+	mu           sync.Mutex
+	published    int64
+	failed       int64
+	deadLettered int64
+	lastError    string
+	lastErrorAt  *time.Time
+}
+
+// NewOutboxRelay returns a valid outbox relay
+func NewOutboxRelay(repo OutboxRepo, eventBus EventBus, pollInterval time.Duration, batchSize int, retryPolicy domain.RetryPolicy) *OutboxRelay {
+	if repo == nil || eventBus == nil {
+		return nil
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &OutboxRelay{
+		Repo:         repo,
+		EventBus:     eventBus,
+		PollInterval: pollInterval,
+		BatchSize:    batchSize,
+		RetryPolicy:  retryPolicy,
+	}
+}
+
+// Run drains the outbox every PollInterval until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches are coming back
+		for {
+			result, err := r.RelayOnce(ctx)
+			if err != nil {
+				fmt.Println("outbox relay failed:", err)
+				break
+			}
+			if result.Published+result.Failed < r.BatchSize || result.Failed > 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes a single batch of pending messages
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (domain.RelayResult, error) {
+	result, err := r.Repo.ProcessPending(ctx, r.BatchSize, r.RetryPolicy, func(message *domain.OutboxMessage) error {
+		// Hand over the envelope so the bus can key it by book. Any other JSON is published as is.
+		var payload interface{} = message.Payload
+		var envelope domain.CloudEvent
+		if err := json.Unmarshal(message.Payload, &envelope); err == nil && envelope.SpecVersion == domain.CloudEventsSpecVersion {
+			payload = &envelope
+		}
+
+		if err := r.EventBus.Publish(ctx, message.Topic, payload); err != nil {
+			r.recordFailure(fmt.Errorf("outbox message %d: %w", message.ID, err))
+			return err
+		}
+		return nil
+	})
+
+	if result.DeadLettered > 0 {
+		fmt.Printf("outbox relay dead-lettered %d messages\n", result.DeadLettered)
+	}
+
+	r.mu.Lock()
+	r.published += int64(result.Published)
+	r.deadLettered += int64(result.DeadLettered)
+	r.mu.Unlock()
+	return result, err
+}
+
+// Stats returns the outbox backlog together with the relay's own counters
+func (r *OutboxRelay) Stats(ctx context.Context) (*domain.OutboxStats, error) {
+	stats, err := r.Repo.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats.Published = r.published
+	stats.Failed = r.failed
+	stats.DeadLettered = r.deadLettered
+	stats.LastError = r.lastError
+	stats.LastErrorAt = r.lastErrorAt
+	return stats, nil
+}
+
+func (r *OutboxRelay) recordFailure(err error) {
+	fmt.Println("outbox relay publish failed:", err)
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+	r.lastError = err.Error()
+	r.lastErrorAt = &now
+}
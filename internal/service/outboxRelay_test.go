@@ -0,0 +1,264 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/infrastructure/repository"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+var errBrokerDown = errors.New("broker down")
+
+func newOutboxRelay(t *testing.T, maxAttempts int) (*service.OutboxRelay, *repository.MemoryOutbox, *repository.MemoryBooks, *servicetest.EventBus) {
+	t.Helper()
+	outbox := repository.NewMemoryOutbox()
+	eventBus := servicetest.NewEventBus()
+	relay := service.NewOutboxRelay(outbox, eventBus, time.Second, 10, domain.RetryPolicy{MaxAttempts: maxAttempts})
+	return relay, outbox, repository.NewMemoryBooksRepo(outbox), eventBus
+}
+
+func relayOnce(t *testing.T, relay *service.OutboxRelay, want domain.RelayResult) {
+	t.Helper()
+	result, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RelayOnce: %v", err)
+	}
+	if result != want {
+		t.Fatalf("RelayOnce returned %+v, want %+v", result, want)
+	}
+}
+
+func assertEventTypes(t *testing.T, eventBus *servicetest.EventBus, want ...string) {
+	t.Helper()
+	if got := eventBus.EventTypes(); !slices.Equal(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestOutboxRelayPublishesInOrder(t *testing.T) {
+	ctx := context.Background()
+	relay, _, repo, eventBus := newOutboxRelay(t, 0)
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	if err := repo.UpdateBookByID(ctx, book.ID, domain.Book{Year: 1951}); err != nil {
+		t.Fatalf("UpdateBookByID: %v", err)
+	}
+	if err := repo.DeleteBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("DeleteBookByID: %v", err)
+	}
+
+	relayOnce(t, relay, domain.RelayResult{Published: 3})
+	assertEventTypes(t, eventBus, domain.BookCreatedEventType, domain.BookUpdatedEventType, domain.BookDeletedEventType)
+	for _, published := range eventBus.Published() {
+		if published.Topic != domain.BookEventsTopic {
+			t.Errorf("published to %q, want %q", published.Topic, domain.BookEventsTopic)
+		}
+	}
+
+	stats, err := relay.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 0 || stats.Published != 3 {
+		t.Errorf("Stats returned %d pending and %d published, want 0 and 3", stats.Pending, stats.Published)
+	}
+}
+
+func TestOutboxRelayHoldsBackFailedBooks(t *testing.T) {
+	ctx := context.Background()
+	relay, _, repo, eventBus := newOutboxRelay(t, 0)
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	if err := repo.UpdateBookByID(ctx, book.ID, domain.Book{Year: 1951}); err != nil {
+		t.Fatalf("UpdateBookByID: %v", err)
+	}
+
+	eventBus.FailWith(errBrokerDown)
+	// The update waits behind the failed creation
+	relayOnce(t, relay, domain.RelayResult{Failed: 1})
+
+	stats, err := relay.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 2 || stats.Failed != 1 || stats.LastError == "" || stats.LastErrorAt == nil {
+		t.Errorf("Stats returned %+v, want 2 pending and the failure recorded", stats)
+	}
+
+	eventBus.FailWith(nil)
+	relayOnce(t, relay, domain.RelayResult{Published: 2})
+	assertEventTypes(t, eventBus, domain.BookCreatedEventType, domain.BookUpdatedEventType)
+}
+
+func TestOutboxRelayDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, repo, eventBus := newOutboxRelay(t, 2)
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+	eventBus.FailWith(errBrokerDown)
+	relayOnce(t, relay, domain.RelayResult{Failed: 1})
+	relayOnce(t, relay, domain.RelayResult{Failed: 1, DeadLettered: 1})
+
+	deadLetters, err := outbox.GetDeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetDeadLetters: %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].AggregateID != book.ID || deadLetters[0].Attempts != 2 {
+		t.Fatalf("GetDeadLetters returned %+v, want one dead letter for book %d after 2 attempts", deadLetters, book.ID)
+	}
+	stats, err := relay.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 0 || stats.DeadLettered != 1 || stats.DeadLettersPending != 1 {
+		t.Errorf("Stats returned %+v, want nothing pending and 1 dead letter", stats)
+	}
+
+	// The update waits until the dead-lettered creation is resolved
+	eventBus.FailWith(nil)
+	if err := repo.UpdateBookByID(ctx, book.ID, domain.Book{Year: 1951}); err != nil {
+		t.Fatalf("UpdateBookByID: %v", err)
+	}
+	relayOnce(t, relay, domain.RelayResult{})
+
+	deadLetterService := service.NewDeadLetterInteractor(outbox)
+	if err := deadLetterService.ReplayDeadLetter(ctx, deadLetters[0].ID); err != nil {
+		t.Fatalf("ReplayDeadLetter: %v", err)
+	}
+	if err := deadLetterService.ReplayDeadLetter(ctx, deadLetters[0].ID); !errors.Is(err, domain.ErrDeadLetterResolved) {
+		t.Fatalf("ReplayDeadLetter twice returned %v, want %v", err, domain.ErrDeadLetterResolved)
+	}
+	relayOnce(t, relay, domain.RelayResult{Published: 2})
+	assertEventTypes(t, eventBus, domain.BookCreatedEventType, domain.BookUpdatedEventType)
+
+	stats, err = relay.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.DeadLettersPending != 0 {
+		t.Errorf("Stats returned %d dead letters pending after the replay, want 0", stats.DeadLettersPending)
+	}
+}
+
+func TestOutboxRelayDiscardsDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	relay, outbox, repo, eventBus := newOutboxRelay(t, 1)
+	book := servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+	eventBus.FailWith(errBrokerDown)
+	relayOnce(t, relay, domain.RelayResult{Failed: 1, DeadLettered: 1})
+	eventBus.FailWith(nil)
+	if err := repo.DeleteBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("DeleteBookByID: %v", err)
+	}
+	relayOnce(t, relay, domain.RelayResult{})
+
+	deadLetters, err := outbox.GetDeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetDeadLetters: %v", err)
+	}
+	deadLetterService := service.NewDeadLetterInteractor(outbox)
+	if err := deadLetterService.DiscardDeadLetter(ctx, deadLetters[0].ID); err != nil {
+		t.Fatalf("DiscardDeadLetter: %v", err)
+	}
+	if err := deadLetterService.ReplayDeadLetter(ctx, deadLetters[0].ID); !errors.Is(err, domain.ErrDeadLetterResolved) {
+		t.Fatalf("ReplayDeadLetter after a discard returned %v, want %v", err, domain.ErrDeadLetterResolved)
+	}
+	relayOnce(t, relay, domain.RelayResult{Published: 1})
+	assertEventTypes(t, eventBus, domain.BookDeletedEventType)
+}
+
+// writingBus updates a book from inside Publish, like a subscriber reacting to an event in the same process
+type writingBus struct {
+	repo    *repository.MemoryBooks
+	updated int
+}
+
+func (b *writingBus) Publish(ctx context.Context, topic string, message interface{}) error {
+	if envelope, ok := message.(*domain.CloudEvent); ok && envelope.Type == domain.BookCreatedEventType {
+		b.updated++
+		return b.repo.UpdateBookByID(ctx, 1, domain.Book{Year: 1951})
+	}
+	return nil
+}
+
+func TestOutboxRelayPublishesWithoutHoldingTheOutbox(t *testing.T) {
+	outbox := repository.NewMemoryOutbox()
+	repo := repository.NewMemoryBooksRepo(outbox)
+	bus := &writingBus{repo: repo}
+	relay := service.NewOutboxRelay(outbox, bus, time.Second, 10, domain.RetryPolicy{})
+	servicetest.CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+	// The first run publishes the creation, the second the update written while publishing it
+	for run := 1; run <= 2; run++ {
+		done := make(chan error, 1)
+		go func() {
+			_, err := relay.RelayOnce(context.Background())
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("RelayOnce: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("RelayOnce run %d deadlocked on a write made while publishing", run)
+		}
+	}
+	stats, err := relay.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if bus.updated != 1 || stats.Published != 2 || stats.Pending != 0 {
+		t.Errorf("bus saw %d creations and Stats returned %+v, want 1 creation and 2 published", bus.updated, stats)
+	}
+}
+
+// rawOutbox hands every payload to publish once, as an outbox holding messages written by another producer would
+type rawOutbox struct {
+	payloads []string
+}
+
+func (o *rawOutbox) ProcessPending(ctx context.Context, limit int, policy domain.RetryPolicy, publish func(*domain.OutboxMessage) error) (domain.RelayResult, error) {
+	var result domain.RelayResult
+	for i, payload := range o.payloads {
+		if err := publish(&domain.OutboxMessage{ID: int64(i + 1), Topic: domain.BookEventsTopic, Payload: json.RawMessage(payload)}); err != nil {
+			return result, err
+		}
+		result.Published++
+	}
+	return result, nil
+}
+
+func (o *rawOutbox) Stats(ctx context.Context) (*domain.OutboxStats, error) {
+	return &domain.OutboxStats{}, nil
+}
+
+func TestOutboxRelayOnlyUnwrapsCloudEvents(t *testing.T) {
+	envelope, err := domain.NewCloudEvent(domain.BookCreated{After: domain.Book{ID: 1, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1937}})
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	wrapped, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	eventBus := servicetest.NewEventBus()
+	outbox := &rawOutbox{payloads: []string{string(wrapped), `{"id":"1","type":"legacy"}`}}
+	relay := service.NewOutboxRelay(outbox, eventBus, time.Second, 10, domain.RetryPolicy{})
+	relayOnce(t, relay, domain.RelayResult{Published: 2})
+
+	published := eventBus.Published()
+	if _, ok := published[0].Message.(*domain.CloudEvent); !ok {
+		t.Errorf("published %T for a CloudEvents payload, want *domain.CloudEvent", published[0].Message)
+	}
+	if _, ok := published[1].Message.(json.RawMessage); !ok {
+		t.Errorf("published %T for a payload without specversion, want it unchanged", published[1].Message)
+	}
+}
@@ -2,19 +2,90 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Redarcher9/Books-Management-System/internal/domain"
 )
 
+// BookRepo stores the books. Its queries arrive normalized by BookInteractor, so repos do not normalize them again.
 type BookRepo interface {
-	GetBooks(ctx context.Context, offset, limit int) ([]*domain.Book, error)
+	GetBooks(ctx context.Context, query domain.BookQuery) (*domain.BookList, error)
+	CountBooks(ctx context.Context, query domain.BookQuery) (int64, error)
+	GetBookFacets(ctx context.Context, query domain.BookQuery, authors int) (*domain.BookFacets, error)
 	GetBookByID(ctx context.Context, ID int) (*domain.Book, error)
 	DeleteBookByID(ctx context.Context, ID int) error
 	UpdateBookByID(ctx context.Context, ID int, book domain.Book) error
 	CreateBook(ctx context.Context, book *domain.Book) error
 }
 
-// create kafka interface
-type KafkaProducer interface {
+// BookHistoryRepo is implemented by repositories that keep every version of a book
+type BookHistoryRepo interface {
+	GetBookAt(ctx context.Context, ID int, at time.Time) (*domain.Book, error)
+}
+
+// BookMergeRepo is implemented by repositories that can merge duplicate books
+type BookMergeRepo interface {
+	MergeBooks(ctx context.Context, targetID int, request domain.BookMergeRequest) (*domain.Book, error)
+}
+
+// BookSimilarityRepo is implemented by repositories that can search books by similar title
+type BookSimilarityRepo interface {
+	FindSimilarBooks(ctx context.Context, title string, limit int) ([]*domain.Book, error)
+}
+
+// BookSearchRepo is implemented by repositories that can search books by the words of their title and author
+type BookSearchRepo interface {
+	SearchBooks(ctx context.Context, query domain.BookSearchQuery) (*domain.BookSearchList, error)
+	CountSearchResults(ctx context.Context, query domain.BookSearchQuery) (int64, error)
+}
+
+// BookSuggestRepo is implemented by repositories that can complete titles and authors as they are typed
+type BookSuggestRepo interface {
+	SuggestBooks(ctx context.Context, query domain.BookSuggestQuery) ([]*domain.BookSuggestion, error)
+}
+
+// BookCacheRepo is implemented by repositories that cache books
+type BookCacheRepo interface {
+	CacheStats(ctx context.Context) (*domain.CacheStats, error)
+	CachedBook(ctx context.Context, ID int) (*domain.CachedBook, error)
+	WarmCache(ctx context.Context, count, pageSize int) (*domain.CacheWarmResult, error)
+	FlushCache(ctx context.Context) (*domain.CacheFlushResult, error)
+}
+
+// EventBus is implemented by the Kafka, Redis Streams and in-process backends
+type EventBus interface {
 	Publish(ctx context.Context, topic string, message interface{}) error
 }
+
+type OutboxRepo interface {
+	ProcessPending(ctx context.Context, limit int, policy domain.RetryPolicy, publish func(*domain.OutboxMessage) error) (domain.RelayResult, error)
+	Stats(ctx context.Context) (*domain.OutboxStats, error)
+}
+
+type DeadLetterRepo interface {
+	GetDeadLetters(ctx context.Context, offset, limit int) ([]*domain.DeadLetter, error)
+	GetDeadLetterByID(ctx context.Context, ID int64) (*domain.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, ID int64) error
+	DiscardDeadLetter(ctx context.Context, ID int64) error
+}
+
+// Projection builds a read model from book events. Apply must be idempotent,
+// because events are replayed at-least-once.
+type Projection interface {
+	Name() string
+	Reset(ctx context.Context) error
+	Apply(ctx context.Context, envelope *domain.CloudEvent, event domain.BookEvent) error
+}
+
+type CheckpointRepo interface {
+	GetCheckpoint(ctx context.Context, projection, topic string, partition int) (nextOffset int64, found bool, err error)
+	SaveCheckpoint(ctx context.Context, projection, topic string, partition int, nextOffset int64) error
+	DeleteCheckpoints(ctx context.Context, projection string) error
+}
+
+// EventStream reads back the published book events
+type EventStream interface {
+	Partitions(ctx context.Context, topic string) ([]int, error)
+	// Read hands every message from start to the current end of the partition to handle
+	Read(ctx context.Context, topic string, partition int, start domain.ReplayFrom, handle func(domain.StreamMessage) error) error
+}
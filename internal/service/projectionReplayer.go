@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+// replayReadAhead is how many decoded messages each partition reader buffers ahead of the merge
+const replayReadAhead = 100
+
+// ProjectionReplayer feeds book events from the stream into projections, checkpointing each partition
+type ProjectionReplayer struct {
+	Stream      EventStream
+	Checkpoints CheckpointRepo
+}
+
+// NewProjectionReplayer returns a valid projection replayer
+func NewProjectionReplayer(stream EventStream, checkpoints CheckpointRepo) *ProjectionReplayer {
+	if stream == nil || checkpoints == nil {
+		return nil
+	}
+	return &ProjectionReplayer{
+		Stream:      stream,
+		Checkpoints: checkpoints,
+	}
+}
+
+// Replay applies the events in topics to projection up to the current end of the stream.
+// With reset the projection and its checkpoints are cleared first, rebuilding it from scratch.
+// Every partition is read at once and the events are applied in event time order across them,
+// so a book's events reach the projection in order even when they were spread over several topics.
+func (r *ProjectionReplayer) Replay(ctx context.Context, projection Projection, topics []string, from domain.ReplayFrom, reset bool) (applied int, err error) {
+	if reset {
+		if err := projection.Reset(ctx); err != nil {
+			return 0, fmt.Errorf("failed to reset projection %s: %w", projection.Name(), err)
+		}
+		if err := r.Checkpoints.DeleteCheckpoints(ctx, projection.Name()); err != nil {
+			return 0, fmt.Errorf("failed to reset checkpoints of %s: %w", projection.Name(), err)
+		}
+	}
+
+	// Stops the readers when the replay ends early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sources []*replaySource
+	for _, topic := range topics {
+		partitions, err := r.Stream.Partitions(ctx, topic)
+		if err != nil {
+			return applied, fmt.Errorf("failed to read partitions of %s: %w", topic, err)
+		}
+
+		for _, partition := range partitions {
+			start, err := r.start(ctx, projection, topic, partition, from)
+			if err != nil {
+				return applied, fmt.Errorf("failed to replay %s/%d: %w", topic, partition, err)
+			}
+			sources = append(sources, r.read(ctx, topic, partition, start))
+		}
+	}
+
+	// Merge the partitions, always applying the earliest of their next events
+	for _, source := range sources {
+		source.next()
+	}
+	for {
+		var earliest *replaySource
+		for _, source := range sources {
+			if source.done {
+				continue
+			}
+			if earliest == nil || source.head.time.Before(earliest.head.time) {
+				earliest = source
+			}
+		}
+		if earliest == nil {
+			break
+		}
+
+		ok, err := r.apply(ctx, projection, earliest.head)
+		if ok {
+			applied++
+		}
+		if err != nil {
+			return applied, fmt.Errorf("failed to replay %s/%d: %w", earliest.topic, earliest.partition, err)
+		}
+		earliest.next()
+	}
+
+	for _, source := range sources {
+		if source.err != nil {
+			return applied, fmt.Errorf("failed to replay %s/%d: %w", source.topic, source.partition, source.err)
+		}
+	}
+	return applied, nil
+}
+
+// start returns where to read the partition from: from, or else the projection's checkpoint
+func (r *ProjectionReplayer) start(ctx context.Context, projection Projection, topic string, partition int, from domain.ReplayFrom) (domain.ReplayFrom, error) {
+	start := from
+	if start.Offset == nil && start.Time == nil {
+		nextOffset, found, err := r.Checkpoints.GetCheckpoint(ctx, projection.Name(), topic, partition)
+		if err != nil {
+			return start, err
+		}
+		if found {
+			start.Offset = &nextOffset
+		}
+	}
+	return start, nil
+}
+
+// replayMessage is a message read from the stream, decoded so it can be ordered by event time
+type replayMessage struct {
+	msg      domain.StreamMessage
+	envelope *domain.CloudEvent
+	event    domain.BookEvent
+	err      error
+	// time is zero for messages that could not be decoded, which are handled first
+	time time.Time
+}
+
+// replaySource is a partition being read in the background, with its next message in head
+type replaySource struct {
+	topic     string
+	partition int
+	messages  chan replayMessage
+	head      replayMessage
+	done      bool
+	// err is the reader's error, set before messages is closed
+	err error
+}
+
+// next moves head to the partition's next message, or marks the source done once it is drained
+func (s *replaySource) next() {
+	var ok bool
+	s.head, ok = <-s.messages
+	s.done = !ok
+}
+
+// read starts reading the partition, decoding each message ahead of the merge
+func (r *ProjectionReplayer) read(ctx context.Context, topic string, partition int, start domain.ReplayFrom) *replaySource {
+	source := &replaySource{
+		topic:     topic,
+		partition: partition,
+		messages:  make(chan replayMessage, replayReadAhead),
+	}
+	go func() {
+		defer close(source.messages)
+		source.err = r.Stream.Read(ctx, topic, partition, start, func(msg domain.StreamMessage) error {
+			message := replayMessage{msg: msg}
+			message.envelope, message.event, message.err = domain.DecodeCloudEvent(msg.Value)
+			if message.err == nil {
+				message.time = message.envelope.Time
+			}
+			select {
+			case source.messages <- message:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return source
+}
+
+// apply hands a decoded message to projection and checkpoints it, reporting whether it was applied
+func (r *ProjectionReplayer) apply(ctx context.Context, projection Projection, message replayMessage) (bool, error) {
+	msg := message.msg
+	applied := false
+	switch err := message.err; {
+	case errors.Is(err, domain.ErrUnknownEventType):
+		// Events this service does not know about yet are not part of any projection
+	case errors.Is(err, domain.ErrUnsupportedSchemaVersion):
+		// Stop rather than silently build a projection with gaps
+		return false, err
+	case err != nil:
+		fmt.Printf("skipping undecodable event at %s/%d@%d: %s\n", msg.Topic, msg.Partition, msg.Offset, err)
+	default:
+		if err := projection.Apply(ctx, message.envelope, message.event); err != nil {
+			return false, err
+		}
+		applied = true
+	}
+	return applied, r.Checkpoints.SaveCheckpoint(ctx, projection.Name(), msg.Topic, msg.Partition, msg.Offset+1)
+}
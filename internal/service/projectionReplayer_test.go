@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"github.com/Redarcher9/Books-Management-System/internal/service/servicetest"
+)
+
+// titles is a projection of the current title of every book
+type titles struct {
+	byID   map[int]string
+	resets int
+}
+
+func newTitles() *titles {
+	return &titles{byID: make(map[int]string)}
+}
+
+func (p *titles) Name() string { return "titles" }
+
+func (p *titles) Reset(ctx context.Context) error {
+	p.resets++
+	p.byID = make(map[int]string)
+	return nil
+}
+
+func (p *titles) Apply(ctx context.Context, envelope *domain.CloudEvent, event domain.BookEvent) error {
+	switch e := event.(type) {
+	case domain.BookCreated:
+		p.byID[e.After.ID] = e.After.Title
+	case domain.BookUpdated:
+		p.byID[e.After.ID] = e.After.Title
+	case domain.BookDeleted:
+		delete(p.byID, e.Before.ID)
+	}
+	return nil
+}
+
+type checkpoints map[string]int64
+
+func checkpointKey(projection, topic string, partition int) string {
+	return fmt.Sprintf("%s/%s/%d", projection, topic, partition)
+}
+
+func (c checkpoints) GetCheckpoint(ctx context.Context, projection, topic string, partition int) (int64, bool, error) {
+	nextOffset, found := c[checkpointKey(projection, topic, partition)]
+	return nextOffset, found, nil
+}
+
+func (c checkpoints) SaveCheckpoint(ctx context.Context, projection, topic string, partition int, nextOffset int64) error {
+	c[checkpointKey(projection, topic, partition)] = nextOffset
+	return nil
+}
+
+func (c checkpoints) DeleteCheckpoints(ctx context.Context, projection string) error {
+	for key := range c {
+		delete(c, key)
+	}
+	return nil
+}
+
+func produceEvent(t *testing.T, stream *servicetest.EventStream, partition int, event domain.BookEvent) {
+	t.Helper()
+	envelope, err := domain.NewCloudEvent(event)
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	produceEnvelope(t, stream, partition, envelope)
+}
+
+func produceEnvelope(t *testing.T, stream *servicetest.EventStream, partition int, envelope *domain.CloudEvent) {
+	t.Helper()
+	produceTo(t, stream, domain.BookEventsTopic, partition, envelope)
+}
+
+func produceTo(t *testing.T, stream *servicetest.EventStream, topic string, partition int, envelope *domain.CloudEvent) {
+	t.Helper()
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	stream.Produce(topic, partition, payload)
+}
+
+func replay(t *testing.T, replayer *service.ProjectionReplayer, projection service.Projection, from domain.ReplayFrom, reset bool, wantApplied int) {
+	t.Helper()
+	applied, err := replayer.Replay(context.Background(), projection, []string{domain.BookEventsTopic}, from, reset)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if applied != wantApplied {
+		t.Errorf("Replay applied %d events, want %d", applied, wantApplied)
+	}
+}
+
+func TestProjectionReplayer(t *testing.T) {
+	stream := servicetest.NewEventStream()
+	saved := checkpoints{}
+	replayer := service.NewProjectionReplayer(stream, saved)
+	projection := newTitles()
+
+	hobbit := domain.Book{ID: 1, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1937}
+	dune := domain.Book{ID: 2, Title: "Dune", Author: "Frank Herbert", Year: 1965}
+	produceEvent(t, stream, 0, domain.BookCreated{After: hobbit})
+	produceEvent(t, stream, 1, domain.BookCreated{After: dune})
+
+	// An event type from a newer producer is checkpointed but not applied
+	unknown, err := domain.NewCloudEvent(domain.BookCreated{After: hobbit})
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	unknown.Type = "com.books-management-system.book.reviewed"
+	produceEnvelope(t, stream, 0, unknown)
+
+	replay(t, replayer, projection, domain.ReplayFrom{}, false, 2)
+	if want := (checkpoints{"titles/book_events/0": 2, "titles/book_events/1": 1}); !maps.Equal(saved, want) {
+		t.Errorf("checkpoints are %v, want %v", saved, want)
+	}
+
+	// The next replay resumes from the checkpoints
+	renamed := hobbit
+	renamed.Title = "The Hobbit, or There and Back Again"
+	produceEvent(t, stream, 0, domain.BookUpdated{Before: hobbit, After: renamed})
+	produceEvent(t, stream, 1, domain.BookDeleted{Before: dune})
+	replay(t, replayer, projection, domain.ReplayFrom{}, false, 2)
+	if want := map[int]string{1: renamed.Title}; !maps.Equal(projection.byID, want) {
+		t.Errorf("projection is %v, want %v", projection.byID, want)
+	}
+
+	// A reset rebuilds the projection from the start of the stream
+	replay(t, replayer, projection, domain.ReplayFrom{}, true, 4)
+	if projection.resets != 1 {
+		t.Errorf("projection was reset %d times, want 1", projection.resets)
+	}
+	if want := map[int]string{1: renamed.Title}; !maps.Equal(projection.byID, want) {
+		t.Errorf("projection is %v after the reset, want %v", projection.byID, want)
+	}
+
+	// An explicit offset overrides the checkpoints
+	offset := int64(2)
+	replay(t, replayer, projection, domain.ReplayFrom{Offset: &offset}, false, 1)
+}
+
+func TestProjectionReplayerStopsOnUnsupportedSchema(t *testing.T) {
+	stream := servicetest.NewEventStream()
+	saved := checkpoints{}
+	replayer := service.NewProjectionReplayer(stream, saved)
+
+	envelope, err := domain.NewCloudEvent(domain.BookCreated{After: domain.Book{ID: 1, Title: "Dune"}})
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	envelope.SchemaVersion = "99.0"
+	produceEnvelope(t, stream, 0, envelope)
+
+	_, err = replayer.Replay(context.Background(), newTitles(), []string{domain.BookEventsTopic}, domain.ReplayFrom{}, false)
+	if !errors.Is(err, domain.ErrUnsupportedSchemaVersion) {
+		t.Fatalf("Replay returned %v, want domain.ErrUnsupportedSchemaVersion", err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("checkpoints are %v, want none past the unsupported event", saved)
+	}
+}
+
+func TestProjectionReplayerOrdersEventsAcrossTopics(t *testing.T) {
+	stream := servicetest.NewEventStream()
+	replayer := service.NewProjectionReplayer(stream, checkpoints{})
+	projection := newTitles()
+
+	// Events that were split by type land on different topics, the deletion in the one read first
+	hobbit := domain.Book{ID: 1, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1937}
+	created, err := domain.NewCloudEvent(domain.BookCreated{After: hobbit})
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	deleted, err := domain.NewCloudEvent(domain.BookDeleted{Before: hobbit})
+	if err != nil {
+		t.Fatalf("NewCloudEvent: %v", err)
+	}
+	deleted.Time = created.Time.Add(time.Second)
+	produceTo(t, stream, "book_deleted", 0, deleted)
+	produceTo(t, stream, "book_created", 0, created)
+
+	applied, err := replayer.Replay(context.Background(), projection, []string{"book_deleted", "book_created"}, domain.ReplayFrom{}, false)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if applied != 2 || len(projection.byID) != 0 {
+		t.Errorf("Replay applied %d events and left %v, want 2 events applied and no books", applied, projection.byID)
+	}
+}
@@ -0,0 +1,922 @@
+// Package servicetest holds conformance suites that every implementation of a service port must pass,
+// so the in-memory, SQLite and Postgres repositories can be swapped for one another, and fakes of
+// the ports backed by Kafka and Redis for testing services without them.
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+	"github.com/Redarcher9/Books-Management-System/internal/service"
+	"gorm.io/gorm"
+)
+
+// NewBookRepo returns an empty repository for a single test
+type NewBookRepo func(t *testing.T) service.BookRepo
+
+// missingID is an ID no test book gets
+const missingID = 404_404
+
+// TestBookRepo runs the service.BookRepo conformance suite against the repositories returned by newRepo.
+// The optional BookMergeRepo, BookSimilarityRepo, BookSearchRepo and BookSuggestRepo behavior is checked when the repository has it.
+func TestBookRepo(t *testing.T, newRepo NewBookRepo) {
+	t.Run("Lifecycle", func(t *testing.T) { testLifecycle(t, newRepo(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, newRepo(t)) })
+	t.Run("Query", func(t *testing.T) { testQuery(t, newRepo(t)) })
+	t.Run("SortOrder", func(t *testing.T) { testSortOrder(t, newRepo(t)) })
+	t.Run("Facets", func(t *testing.T) { testFacets(t, newRepo(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newRepo) })
+	t.Run("Duplicates", func(t *testing.T) { testDuplicates(t, newRepo(t)) })
+	t.Run("CacheInvalidation", func(t *testing.T) { testCacheInvalidation(t, newRepo) })
+	t.Run("Merge", func(t *testing.T) { testMerge(t, newRepo(t)) })
+	t.Run("FindSimilar", func(t *testing.T) { testFindSimilar(t, newRepo(t)) })
+	t.Run("Search", func(t *testing.T) { testSearch(t, newRepo(t)) })
+	t.Run("Suggest", func(t *testing.T) { testSuggest(t, newRepo(t)) })
+}
+
+func testLifecycle(t *testing.T, repo service.BookRepo) {
+	ctx := context.Background()
+	book := CreateBook(t, repo, "The Hobit", "J.R.R. Tolkien", 1937)
+	if book.ID <= 0 {
+		t.Fatalf("CreateBook set ID %d, want a positive ID", book.ID)
+	}
+	assertBook(t, repo, book)
+
+	other := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+	if other.ID == book.ID {
+		t.Errorf("CreateBook reused ID %d", book.ID)
+	}
+
+	// Fields left empty keep their value
+	if err := repo.UpdateBookByID(ctx, book.ID, domain.Book{Title: "The Hobbit"}); err != nil {
+		t.Fatalf("UpdateBookByID(%d): %v", book.ID, err)
+	}
+	book.Title = "The Hobbit"
+	assertBook(t, repo, book)
+
+	if err := repo.DeleteBookByID(ctx, book.ID); err != nil {
+		t.Fatalf("DeleteBookByID(%d): %v", book.ID, err)
+	}
+	assertNotFound(t, repo, book.ID)
+	assertBook(t, repo, other)
+}
+
+func testPagination(t *testing.T, repo service.BookRepo) {
+	books := []domain.Book{
+		CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937),
+		CreateBook(t, repo, "Dune", "Frank Herbert", 1965),
+		CreateBook(t, repo, "Emma", "Jane Austen", 1815),
+		CreateBook(t, repo, "Ulysses", "James Joyce", 1922),
+		CreateBook(t, repo, "Beloved", "Toni Morrison", 1987),
+	}
+
+	after := func(i int) *domain.BookCursor { return &domain.BookCursor{Book: books[i]} }
+	before := func(i int) *domain.BookCursor { return &domain.BookCursor{Book: books[i], Before: true} }
+
+	tests := []struct {
+		name     string
+		cursor   *domain.BookCursor
+		limit    int
+		want     []domain.Book
+		wantMore bool
+	}{
+		{name: "first page", limit: 2, want: books[0:2], wantMore: true},
+		{name: "next page", cursor: after(1), limit: 2, want: books[2:4], wantMore: true},
+		{name: "partial last page", cursor: after(3), limit: 2, want: books[4:]},
+		{name: "after the last book", cursor: after(4), limit: 2},
+		{name: "limit above total", limit: 10, want: books},
+		{name: "cursor inside a page", cursor: after(0), limit: 3, want: books[1:4], wantMore: true},
+		{name: "previous page", cursor: before(4), limit: 2, want: books[2:4], wantMore: true},
+		{name: "first page backwards", cursor: before(2), limit: 2, want: books[0:2]},
+		{name: "before the first book", cursor: before(0), limit: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.BookQuery{Cursor: tt.cursor, Limit: tt.limit}
+			got, err := repo.GetBooks(context.Background(), query.Normalize())
+			if err != nil {
+				t.Fatalf("GetBooks(%+v): %v", query, err)
+			}
+			assertBooks(t, got.Books, tt.want...)
+			if got.More != tt.wantMore {
+				t.Errorf("GetBooks(%+v) reported more books %t, want %t", query, got.More, tt.wantMore)
+			}
+		})
+	}
+}
+
+func testQuery(t *testing.T, repo service.BookRepo) {
+	books := []domain.Book{
+		CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937),
+		CreateBook(t, repo, "The Silmarillion", "J.R.R. Tolkien", 1977),
+		CreateBook(t, repo, "Dune", "Frank Herbert", 1965),
+		CreateBook(t, repo, "Dune Messiah", "Frank Herbert", 1969),
+		CreateBook(t, repo, "Beloved", "Toni Morrison", 1987),
+		CreateBook(t, repo, "The Lord of the Rings", "j.r.r.  tolkien", 1954),
+	}
+	pick := func(indexes ...int) []domain.Book {
+		picked := make([]domain.Book, 0, len(indexes))
+		for _, i := range indexes {
+			picked = append(picked, books[i])
+		}
+		return picked
+	}
+
+	tests := []struct {
+		name  string
+		query domain.BookQuery
+		want  []domain.Book
+	}{
+		{name: "author ignoring case and spacing", query: domain.BookQuery{Author: " J.R.R. TOLKIEN"}, want: pick(0, 1, 5)},
+		{name: "author must match whole", query: domain.BookQuery{Author: "Tolkien"}},
+		{name: "title substring", query: domain.BookQuery{Title: "DUNE"}, want: pick(2, 3)},
+		{name: "title wildcards are literal", query: domain.BookQuery{Title: "%"}},
+		{name: "year range", query: domain.BookQuery{YearFrom: 1950, YearTo: 1970}, want: pick(2, 3, 5)},
+		{name: "year from", query: domain.BookQuery{YearFrom: 1966}, want: pick(1, 3, 4)},
+		{name: "year to", query: domain.BookQuery{YearTo: 1940}, want: pick(0)},
+		{name: "ids", query: domain.BookQuery{IDs: []int{books[4].ID, books[0].ID, books[4].ID, missingID}}, want: pick(0, 4)},
+		{name: "combined filters", query: domain.BookQuery{Author: "j.r.r. tolkien", YearFrom: 1950}, want: pick(1, 5)},
+		{name: "sort descending", query: domain.BookQuery{Sort: domain.ParseBookSort("-year")}, want: pick(4, 1, 3, 2, 5, 0)},
+		{name: "sort by title", query: domain.BookQuery{Sort: domain.ParseBookSort("title")}, want: pick(4, 2, 3, 0, 5, 1)},
+		{name: "sort by two fields", query: domain.BookQuery{Sort: domain.ParseBookSort("author,-year")}, want: pick(3, 2, 1, 5, 0, 4)},
+		{name: "ties broken by ID", query: domain.BookQuery{Sort: domain.ParseBookSort("author")}, want: pick(2, 3, 0, 1, 5, 4)},
+		{name: "sort by ID descending", query: domain.BookQuery{Sort: domain.ParseBookSort("-id")}, want: pick(5, 4, 3, 2, 1, 0)},
+		{name: "sorted page", query: domain.BookQuery{Sort: domain.ParseBookSort("-year"), Cursor: &domain.BookCursor{Book: books[1]}, Limit: 2}, want: pick(3, 2)},
+		{name: "sorted page backwards", query: domain.BookQuery{Sort: domain.ParseBookSort("-year"), Cursor: &domain.BookCursor{Book: books[5], Before: true}, Limit: 2}, want: pick(3, 2)},
+		{name: "page sorted by two fields", query: domain.BookQuery{Sort: domain.ParseBookSort("author,-year"), Cursor: &domain.BookCursor{Book: books[2]}, Limit: 3}, want: pick(1, 5, 0)},
+		{name: "filtered page", query: domain.BookQuery{Author: "j.r.r. tolkien", Cursor: &domain.BookCursor{Book: books[0]}, Limit: 1}, want: pick(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.query.Limit == 0 {
+				tt.query.Limit = 10
+			}
+			got, err := repo.GetBooks(context.Background(), tt.query.Normalize())
+			if err != nil {
+				t.Fatalf("GetBooks(%+v): %v", tt.query, err)
+			}
+			assertBooks(t, got.Books, tt.want...)
+		})
+	}
+
+	counts := []struct {
+		name  string
+		query domain.BookQuery
+		want  int64
+	}{
+		{name: "count all", want: 6},
+		{name: "count by author", query: domain.BookQuery{Author: "J.R.R. Tolkien"}, want: 3},
+		{name: "count ignores the page", query: domain.BookQuery{YearFrom: 1950, Cursor: &domain.BookCursor{Book: books[3]}, Limit: 1}, want: 5},
+		{name: "count nothing", query: domain.BookQuery{Title: "%"}, want: 0},
+	}
+	for _, tt := range counts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CountBooks(context.Background(), tt.query.Normalize())
+			if err != nil {
+				t.Fatalf("CountBooks(%+v): %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("CountBooks(%+v) = %d, want %d", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// A cached filtered list must not outlive a write
+	t.Run("filtered list after a write", func(t *testing.T) {
+		query := domain.BookQuery{Author: "J.R.R. Tolkien", Limit: 10}
+		for i := 0; i < 2; i++ {
+			if _, err := repo.GetBooks(context.Background(), query.Normalize()); err != nil {
+				t.Fatalf("GetBooks(%+v): %v", query, err)
+			}
+		}
+		if err := repo.UpdateBookByID(context.Background(), books[5].ID, domain.Book{Author: "Christopher Tolkien"}); err != nil {
+			t.Fatalf("UpdateBookByID(%d): %v", books[5].ID, err)
+		}
+		got, err := repo.GetBooks(context.Background(), query.Normalize())
+		if err != nil {
+			t.Fatalf("GetBooks(%+v): %v", query, err)
+		}
+		assertBooks(t, got.Books, pick(0, 1)...)
+	})
+}
+
+// testSortOrder checks that titles and authors are sorted byte by byte, like BookSort.Compare,
+// and not by a collation that skips punctuation and spaces
+func testSortOrder(t *testing.T, repo service.BookRepo) {
+	books := []domain.Book{
+		CreateBook(t, repo, "Postman", "Ann Lee", 1985),
+		CreateBook(t, repo, "Post-War", "Anne Lee", 2005),
+		CreateBook(t, repo, "Post War", "Ann-Marie Lee", 1995),
+	}
+
+	tests := []struct {
+		sort string
+		want []domain.Book
+	}{
+		{sort: "title", want: []domain.Book{books[2], books[1], books[0]}},
+		{sort: "-title", want: []domain.Book{books[0], books[1], books[2]}},
+		{sort: "author", want: []domain.Book{books[0], books[2], books[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			query := domain.BookQuery{Sort: domain.ParseBookSort(tt.sort), Limit: 10}.Normalize()
+			got, err := repo.GetBooks(context.Background(), query)
+			if err != nil {
+				t.Fatalf("GetBooks(%+v): %v", query, err)
+			}
+			assertBooks(t, got.Books, tt.want...)
+
+			// Paging from the first book must agree with the order of the full list
+			query.Cursor = &domain.BookCursor{Book: tt.want[0]}
+			got, err = repo.GetBooks(context.Background(), query)
+			if err != nil {
+				t.Fatalf("GetBooks(%+v): %v", query, err)
+			}
+			assertBooks(t, got.Books, tt.want[1:]...)
+		})
+	}
+}
+
+func testFacets(t *testing.T, repo service.BookRepo) {
+	ctx := context.Background()
+	CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	CreateBook(t, repo, "The Silmarillion", "J.R.R. Tolkien", 1977)
+	CreateBook(t, repo, "The Lord of the Rings", "J.R.R. Tolkien", 1954)
+	dune := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+	CreateBook(t, repo, "Dune Messiah", "Frank Herbert", 1969)
+	CreateBook(t, repo, "Beloved", "Toni Morrison", 1987)
+	CreateBook(t, repo, "Sula", "Toni Morrison", 1973)
+	gone := CreateBook(t, repo, "Emma", "Jane Austen", 1815)
+	deleteBook(t, repo, gone.ID)
+
+	tolkien, herbert, morrison := domain.AuthorCount{Author: "J.R.R. Tolkien"}, domain.AuthorCount{Author: "Frank Herbert"}, domain.AuthorCount{Author: "Toni Morrison"}
+	authors := func(books int64, author domain.AuthorCount) domain.AuthorCount {
+		author.Books = books
+		return author
+	}
+
+	// decades and years take pairs of a decade or year and its number of books
+	decades := func(pairs ...int) []domain.DecadeCount {
+		counts := make([]domain.DecadeCount, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			counts = append(counts, domain.DecadeCount{Decade: pairs[i], Books: int64(pairs[i+1])})
+		}
+		return counts
+	}
+	years := func(pairs ...int) []domain.YearCount {
+		counts := make([]domain.YearCount, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			counts = append(counts, domain.YearCount{Year: pairs[i], Books: int64(pairs[i+1])})
+		}
+		return counts
+	}
+
+	tests := []struct {
+		name        string
+		query       domain.BookQuery
+		authors     int
+		wantTotal   int64
+		wantAuthors []domain.AuthorCount
+		wantDecades []domain.DecadeCount
+		wantYears   []domain.YearCount
+	}{
+		{
+			name:        "every book",
+			wantTotal:   7,
+			wantAuthors: []domain.AuthorCount{authors(3, tolkien), authors(2, herbert), authors(2, morrison)},
+			wantDecades: decades(1930, 1, 1950, 1, 1960, 2, 1970, 2, 1980, 1),
+			wantYears:   years(1937, 1, 1954, 1, 1965, 1, 1969, 1, 1973, 1, 1977, 1, 1987, 1),
+		},
+		{
+			name:        "top author",
+			authors:     1,
+			wantTotal:   7,
+			wantAuthors: []domain.AuthorCount{authors(3, tolkien)},
+			wantDecades: decades(1930, 1, 1950, 1, 1960, 2, 1970, 2, 1980, 1),
+			wantYears:   years(1937, 1, 1954, 1, 1965, 1, 1969, 1, 1973, 1, 1977, 1, 1987, 1),
+		},
+		{
+			name:        "year from",
+			query:       domain.BookQuery{YearFrom: 1960},
+			wantTotal:   5,
+			wantAuthors: []domain.AuthorCount{authors(2, herbert), authors(2, morrison), authors(1, tolkien)},
+			wantDecades: decades(1960, 2, 1970, 2, 1980, 1),
+			wantYears:   years(1965, 1, 1969, 1, 1973, 1, 1977, 1, 1987, 1),
+		},
+		{
+			name:        "author ignoring case",
+			query:       domain.BookQuery{Author: "j.r.r. tolkien", Sort: domain.ParseBookSort("-year")},
+			wantTotal:   3,
+			wantAuthors: []domain.AuthorCount{authors(3, tolkien)},
+			wantDecades: decades(1930, 1, 1950, 1, 1970, 1),
+			wantYears:   years(1937, 1, 1954, 1, 1977, 1),
+		},
+		{
+			name:  "nothing",
+			query: domain.BookQuery{Title: "%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.authors == 0 {
+				tt.authors = 10
+			}
+			got, err := repo.GetBookFacets(ctx, tt.query.Normalize(), tt.authors)
+			if err != nil {
+				t.Fatalf("GetBookFacets(%+v): %v", tt.query, err)
+			}
+			assertFacets(t, got, &domain.BookFacets{Total: tt.wantTotal, Authors: tt.wantAuthors, Decades: tt.wantDecades, Years: tt.wantYears})
+		})
+	}
+
+	// Cached counts must not outlive a write
+	t.Run("facets after a write", func(t *testing.T) {
+		query := domain.BookQuery{Title: "dune"}
+		if _, err := repo.GetBookFacets(ctx, query.Normalize(), 10); err != nil {
+			t.Fatalf("GetBookFacets(%+v): %v", query, err)
+		}
+		if err := repo.UpdateBookByID(ctx, dune.ID, domain.Book{Year: 1966}); err != nil {
+			t.Fatalf("UpdateBookByID(%d): %v", dune.ID, err)
+		}
+		got, err := repo.GetBookFacets(ctx, query.Normalize(), 10)
+		if err != nil {
+			t.Fatalf("GetBookFacets(%+v): %v", query, err)
+		}
+		assertFacets(t, got, &domain.BookFacets{
+			Total:   2,
+			Authors: []domain.AuthorCount{authors(2, herbert)},
+			Decades: decades(1960, 2),
+			Years:   years(1966, 1, 1969, 1),
+		})
+	})
+}
+
+func testNotFound(t *testing.T, newRepo NewBookRepo) {
+	tests := []struct {
+		name    string
+		merge   bool // needs a service.BookMergeRepo
+		op      func(t *testing.T, repo service.BookRepo, existing domain.Book) error
+		wantErr error
+	}{
+		{
+			name: "get missing book",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				_, err := repo.GetBookByID(context.Background(), missingID)
+				return err
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "update missing book",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				return repo.UpdateBookByID(context.Background(), missingID, domain.Book{Title: "Dune"})
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "delete missing book is a no-op",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				return repo.DeleteBookByID(context.Background(), missingID)
+			},
+		},
+		{
+			name: "get deleted book",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				deleteBook(t, repo, existing.ID)
+				_, err := repo.GetBookByID(context.Background(), existing.ID)
+				return err
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "update deleted book",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				deleteBook(t, repo, existing.ID)
+				return repo.UpdateBookByID(context.Background(), existing.ID, domain.Book{Title: "Dune"})
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "delete deleted book is a no-op",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				deleteBook(t, repo, existing.ID)
+				return repo.DeleteBookByID(context.Background(), existing.ID)
+			},
+		},
+		{
+			name: "list without books is empty",
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				deleteBook(t, repo, existing.ID)
+				list, err := repo.GetBooks(context.Background(), domain.BookQuery{Limit: 10}.Normalize())
+				if err == nil && len(list.Books) > 0 {
+					t.Errorf("GetBooks returned %d books, want none", len(list.Books))
+				}
+				return err
+			},
+		},
+		{
+			name:  "merge into missing book",
+			merge: true,
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				_, err := repo.(service.BookMergeRepo).MergeBooks(context.Background(), missingID, domain.BookMergeRequest{SourceID: existing.ID})
+				return err
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:  "merge missing book",
+			merge: true,
+			op: func(t *testing.T, repo service.BookRepo, existing domain.Book) error {
+				_, err := repo.(service.BookMergeRepo).MergeBooks(context.Background(), existing.ID, domain.BookMergeRequest{SourceID: missingID})
+				return err
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			if _, ok := repo.(service.BookMergeRepo); tt.merge && !ok {
+				t.Skip("repository does not merge books")
+			}
+			existing := CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+
+			err := tt.op(t, repo, existing)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("returned %v, want nil", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("returned %v, want %v", err, tt.wantErr)
+			}
+			// A failed write leaves the existing book alone
+			if tt.merge {
+				assertBook(t, repo, existing)
+			}
+		})
+	}
+}
+
+func testDuplicates(t *testing.T, repo service.BookRepo) {
+	ctx := context.Background()
+	hobbit := CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	CreateBook(t, repo, "Les Misérables", "Victor Hugo", 1862)
+
+	createTests := []struct {
+		name          string
+		title, author string
+		wantDuplicate bool
+	}{
+		{name: "same title and author", title: "The Hobbit", author: "J.R.R. Tolkien", wantDuplicate: true},
+		{name: "different case", title: "THE HOBBIT", author: "j.r.r. tolkien", wantDuplicate: true},
+		{name: "extra whitespace", title: "  The   Hobbit ", author: "J.R.R.  Tolkien", wantDuplicate: true},
+		{name: "decomposed accents", title: "Les Mise\u0301rables", author: "Victor Hugo", wantDuplicate: true},
+		{name: "different author", title: "The Hobbit", author: "Christopher Tolkien"},
+		{name: "different title", title: "The Silmarillion", author: "J.R.R. Tolkien"},
+		{name: "punctuation differs", title: "The Hobbit!", author: "J.R.R. Tolkien"},
+	}
+	for _, tt := range createTests {
+		t.Run("create "+tt.name, func(t *testing.T) {
+			book := domain.Book{Title: tt.title, Author: tt.author, Year: 1951}
+			err := repo.CreateBook(ctx, &book)
+			if tt.wantDuplicate && !errors.Is(err, gorm.ErrDuplicatedKey) {
+				t.Errorf("CreateBook(%q, %q) returned %v, want gorm.ErrDuplicatedKey", tt.title, tt.author, err)
+			}
+			if !tt.wantDuplicate && err != nil {
+				t.Errorf("CreateBook(%q, %q): %v", tt.title, tt.author, err)
+			}
+		})
+	}
+
+	other := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+	updateTests := []struct {
+		name          string
+		ID            int
+		book          domain.Book
+		wantDuplicate bool
+	}{
+		{name: "to another book", ID: other.ID, book: domain.Book{Title: "the hobbit", Author: "J.R.R. Tolkien"}, wantDuplicate: true},
+		{name: "to another book with decomposed accents", ID: other.ID, book: domain.Book{Title: "Les Mise\u0301rables", Author: "Victor Hugo"}, wantDuplicate: true},
+		{name: "to its own title in another case", ID: hobbit.ID, book: domain.Book{Title: "The HOBBIT"}},
+		{name: "year only", ID: hobbit.ID, book: domain.Book{Year: 1951}},
+	}
+	for _, tt := range updateTests {
+		t.Run("update "+tt.name, func(t *testing.T) {
+			err := repo.UpdateBookByID(ctx, tt.ID, tt.book)
+			if tt.wantDuplicate && !errors.Is(err, gorm.ErrDuplicatedKey) {
+				t.Errorf("UpdateBookByID(%d) returned %v, want gorm.ErrDuplicatedKey", tt.ID, err)
+			}
+			if !tt.wantDuplicate && err != nil {
+				t.Errorf("UpdateBookByID(%d): %v", tt.ID, err)
+			}
+		})
+	}
+	// The rejected updates left the book alone
+	assertBook(t, repo, other)
+
+	t.Run("create after deleting the original", func(t *testing.T) {
+		deleteBook(t, repo, hobbit.ID)
+		CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	})
+}
+
+// testCacheInvalidation reads every book and page before a write, so a cached repository holds all of
+// them, and checks that the write is visible right after it. Uncached repositories pass trivially.
+func testCacheInvalidation(t *testing.T, newRepo NewBookRepo) {
+	tests := []struct {
+		name  string
+		merge bool // needs a service.BookMergeRepo
+		// write changes repo holding books, and returns the books it should hold afterwards and the IDs that should be gone
+		write func(t *testing.T, repo service.BookRepo, books []domain.Book) (want []domain.Book, gone []int)
+	}{
+		{
+			name: "create",
+			write: func(t *testing.T, repo service.BookRepo, books []domain.Book) ([]domain.Book, []int) {
+				created := CreateBook(t, repo, "Emma", "Jane Austen", 1815)
+				return append(books, created), nil
+			},
+		},
+		{
+			name: "update",
+			write: func(t *testing.T, repo service.BookRepo, books []domain.Book) ([]domain.Book, []int) {
+				if err := repo.UpdateBookByID(context.Background(), books[0].ID, domain.Book{Title: "The Hobbit, or There and Back Again"}); err != nil {
+					t.Fatalf("UpdateBookByID(%d): %v", books[0].ID, err)
+				}
+				books[0].Title = "The Hobbit, or There and Back Again"
+				return books, nil
+			},
+		},
+		{
+			name: "delete",
+			write: func(t *testing.T, repo service.BookRepo, books []domain.Book) ([]domain.Book, []int) {
+				deleteBook(t, repo, books[0].ID)
+				return books[1:], []int{books[0].ID}
+			},
+		},
+		{
+			name:  "merge",
+			merge: true,
+			write: func(t *testing.T, repo service.BookRepo, books []domain.Book) ([]domain.Book, []int) {
+				request := domain.BookMergeRequest{SourceID: books[1].ID, Year: domain.MergeKeepSource}
+				if _, err := repo.(service.BookMergeRepo).MergeBooks(context.Background(), books[0].ID, request); err != nil {
+					t.Fatalf("MergeBooks(%d, %d): %v", books[0].ID, books[1].ID, err)
+				}
+				books[0].Year = books[1].Year
+				return books[:1], []int{books[1].ID}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			if _, ok := repo.(service.BookMergeRepo); tt.merge && !ok {
+				t.Skip("repository does not merge books")
+			}
+			books := []domain.Book{
+				CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937),
+				CreateBook(t, repo, "Dune", "Frank Herbert", 1965),
+			}
+			warm(t, repo, books)
+
+			want, gone := tt.write(t, repo, books)
+			// Twice, so the second read comes from the cache that the first one filled
+			for i := 0; i < 2; i++ {
+				got, err := repo.GetBooks(context.Background(), domain.BookQuery{Limit: 10}.Normalize())
+				if err != nil {
+					t.Fatalf("GetBooks: %v", err)
+				}
+				assertBooks(t, got.Books, want...)
+				for _, book := range want {
+					assertBook(t, repo, book)
+				}
+				for _, ID := range gone {
+					assertNotFound(t, repo, ID)
+				}
+			}
+		})
+	}
+}
+
+// warm reads the books, the list and the IDs around them, including the next ID to be created
+func warm(t *testing.T, repo service.BookRepo, books []domain.Book) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := repo.GetBooks(ctx, domain.BookQuery{Limit: 10}.Normalize()); err != nil {
+		t.Fatalf("GetBooks: %v", err)
+	}
+	for _, book := range books {
+		assertBook(t, repo, book)
+	}
+	next := books[len(books)-1].ID + 1
+	if _, err := repo.GetBookByID(ctx, next); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetBookByID(%d) returned %v, want gorm.ErrRecordNotFound", next, err)
+	}
+}
+
+func testMerge(t *testing.T, repo service.BookRepo) {
+	mergeRepo, ok := repo.(service.BookMergeRepo)
+	if !ok {
+		t.Skip("repository does not merge books")
+	}
+	ctx := context.Background()
+	target := CreateBook(t, repo, "The Hobbit", "Tolkien", 1937)
+	source := CreateBook(t, repo, "Hobbit, The", "J.R.R. Tolkien", 1951)
+	third := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+
+	merged, err := mergeRepo.MergeBooks(ctx, target.ID, domain.BookMergeRequest{SourceID: source.ID, Author: domain.MergeKeepSource})
+	if err != nil {
+		t.Fatalf("MergeBooks(%d, %d): %v", target.ID, source.ID, err)
+	}
+	want := domain.Book{ID: target.ID, Title: "The Hobbit", Author: "J.R.R. Tolkien", Year: 1937}
+	if *merged != want {
+		t.Errorf("MergeBooks returned %+v, want %+v", *merged, want)
+	}
+	assertBook(t, repo, want)
+	assertNotFound(t, repo, source.ID)
+
+	if _, err := mergeRepo.MergeBooks(ctx, target.ID, domain.BookMergeRequest{SourceID: source.ID}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("MergeBooks of a merged source book returned %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	// Taking over the title of the source book would make the target a duplicate of another book
+	existing := CreateBook(t, repo, "Dune Messiah", "Frank Herbert", 1969)
+	other := CreateBook(t, repo, "Dune Messiah", "Herbert", 1969)
+	request := domain.BookMergeRequest{SourceID: other.ID, Title: domain.MergeKeepSource}
+	if _, err := mergeRepo.MergeBooks(ctx, third.ID, request); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("MergeBooks into a duplicate returned %v, want gorm.ErrDuplicatedKey", err)
+	}
+	assertBook(t, repo, third)
+	assertBook(t, repo, other)
+	assertBook(t, repo, existing)
+}
+
+func testFindSimilar(t *testing.T, repo service.BookRepo) {
+	similarityRepo, ok := repo.(service.BookSimilarityRepo)
+	if !ok {
+		t.Skip("repository does not search similar books")
+	}
+	ctx := context.Background()
+	hobbit := CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+
+	books, err := similarityRepo.FindSimilarBooks(ctx, "Hobbit, The", 10)
+	if err != nil {
+		t.Fatalf("FindSimilarBooks: %v", err)
+	}
+	assertBooks(t, books, hobbit)
+}
+
+func testSearch(t *testing.T, repo service.BookRepo) {
+	searchRepo, ok := repo.(service.BookSearchRepo)
+	if !ok {
+		t.Skip("repository does not search books")
+	}
+	ctx := context.Background()
+	hobbit := CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	silmarillion := CreateBook(t, repo, "The Silmarillion", "J.R.R. Tolkien", 1977)
+	biography := CreateBook(t, repo, "Tolkien: A Biography", "Humphrey Carpenter", 1977)
+	dune := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+	gone := CreateBook(t, repo, "Tolkien and the Great War", "John Garth", 2003)
+	deleteBook(t, repo, gone.ID)
+
+	search := func(t *testing.T, query domain.BookSearchQuery) *domain.BookSearchList {
+		t.Helper()
+		if query.Limit == 0 {
+			query.Limit = 10
+		}
+		list, err := searchRepo.SearchBooks(ctx, query.Normalize())
+		if err != nil {
+			t.Fatalf("SearchBooks(%+v): %v", query, err)
+		}
+		return list
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []domain.Book
+	}{
+		{name: "title ranks above author", text: "tolkien", want: []domain.Book{biography, hobbit, silmarillion}},
+		{name: "ignoring case", text: "DUNE", want: []domain.Book{dune}},
+		{name: "every word must match", text: "hobbit tolkien", want: []domain.Book{hobbit}},
+		{name: "words across title and author", text: "silmarillion tolkien", want: []domain.Book{silmarillion}},
+		{name: "no match", text: "austen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertHits(t, search(t, domain.BookSearchQuery{Text: tt.text}).Hits, tt.want...)
+		})
+	}
+
+	t.Run("highlights", func(t *testing.T) {
+		hits := search(t, domain.BookSearchQuery{Text: "hobbit tolkien"}).Hits
+		assertHits(t, hits, hobbit)
+		if got, want := hits[0].TitleHighlight, "The <mark>Hobbit</mark>"; got != want {
+			t.Errorf("title highlight = %q, want %q", got, want)
+		}
+		if got, want := hits[0].AuthorHighlight, "J.R.R. <mark>Tolkien</mark>"; got != want {
+			t.Errorf("author highlight = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		first := search(t, domain.BookSearchQuery{Text: "tolkien", Limit: 2})
+		assertHits(t, first.Hits, biography, hobbit)
+		if !first.More {
+			t.Errorf("first page reported no more results")
+		}
+
+		last := first.Hits[1]
+		next := search(t, domain.BookSearchQuery{Text: "tolkien", Limit: 2, Cursor: &domain.BookSearchCursor{Rank: last.Rank, ID: last.ID}})
+		assertHits(t, next.Hits, silmarillion)
+		if next.More {
+			t.Errorf("last page reported more results")
+		}
+
+		prev := search(t, domain.BookSearchQuery{Text: "tolkien", Limit: 2, Cursor: &domain.BookSearchCursor{Rank: next.Hits[0].Rank, ID: next.Hits[0].ID, Before: true}})
+		assertHits(t, prev.Hits, biography, hobbit)
+		if prev.More {
+			t.Errorf("page before the first result reported more results")
+		}
+	})
+
+	counts := []struct {
+		text string
+		want int64
+	}{
+		{text: "tolkien", want: 3},
+		{text: "austen", want: 0},
+	}
+	for _, tt := range counts {
+		t.Run("count "+tt.text, func(t *testing.T) {
+			got, err := searchRepo.CountSearchResults(ctx, domain.BookSearchQuery{Text: tt.text}.Normalize())
+			if err != nil {
+				t.Fatalf("CountSearchResults(%q): %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("CountSearchResults(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	// Cached results must not outlive a write
+	t.Run("search after a write", func(t *testing.T) {
+		search(t, domain.BookSearchQuery{Text: "herbert"})
+		if err := repo.UpdateBookByID(ctx, dune.ID, domain.Book{Author: "Brian Herbert"}); err != nil {
+			t.Fatalf("UpdateBookByID(%d): %v", dune.ID, err)
+		}
+		dune.Author = "Brian Herbert"
+		assertHits(t, search(t, domain.BookSearchQuery{Text: "brian"}).Hits, dune)
+	})
+}
+
+func testSuggest(t *testing.T, repo service.BookRepo) {
+	suggestRepo, ok := repo.(service.BookSuggestRepo)
+	if !ok {
+		t.Skip("repository does not suggest books")
+	}
+	ctx := context.Background()
+	CreateBook(t, repo, "The Hobbit", "J.R.R. Tolkien", 1937)
+	CreateBook(t, repo, "The Silmarillion", "J.R.R. Tolkien", 1977)
+	CreateBook(t, repo, "The Hobbit", "Tolkien", 1951)
+	CreateBook(t, repo, "Tolkien: A Biography", "Humphrey Carpenter", 1977)
+	dune := CreateBook(t, repo, "Dune", "Frank Herbert", 1965)
+	gone := CreateBook(t, repo, "Hobbit Lessons", "Anonymous", 2001)
+	deleteBook(t, repo, gone.ID)
+
+	title := func(text string, books int64) domain.BookSuggestion {
+		return domain.BookSuggestion{Field: domain.BookSuggestionTitle, Text: text, Books: books}
+	}
+	author := func(text string, books int64) domain.BookSuggestion {
+		return domain.BookSuggestion{Field: domain.BookSuggestionAuthor, Text: text, Books: books}
+	}
+
+	tests := []struct {
+		name  string
+		query domain.BookSuggestQuery
+		want  []domain.BookSuggestion
+	}{
+		{name: "word prefix", query: domain.BookSuggestQuery{Prefix: "hob"}, want: []domain.BookSuggestion{title("The Hobbit", 2)}},
+		{name: "ignoring case", query: domain.BookSuggestQuery{Prefix: " SILM"}, want: []domain.BookSuggestion{title("The Silmarillion", 1)}},
+		{name: "titles and authors", query: domain.BookSuggestQuery{Prefix: "tolk"}, want: []domain.BookSuggestion{author("J.R.R. Tolkien", 2), author("Tolkien", 1), title("Tolkien: A Biography", 1)}},
+		{name: "limit", query: domain.BookSuggestQuery{Prefix: "tolk", Limit: 1}, want: []domain.BookSuggestion{author("J.R.R. Tolkien", 2)}},
+		{name: "typo", query: domain.BookSuggestQuery{Prefix: "hobit"}, want: []domain.BookSuggestion{title("The Hobbit", 2)}},
+		{name: "prefix of a later word", query: domain.BookSuggestQuery{Prefix: "herb"}, want: []domain.BookSuggestion{author("Frank Herbert", 1)}},
+		{name: "no match", query: domain.BookSuggestQuery{Prefix: "austen"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.query.Limit == 0 {
+				tt.query.Limit = 5
+			}
+			got, err := suggestRepo.SuggestBooks(ctx, tt.query.Normalize())
+			if err != nil {
+				t.Fatalf("SuggestBooks(%+v): %v", tt.query, err)
+			}
+			assertSuggestions(t, got, tt.want...)
+		})
+	}
+
+	// Cached suggestions must not outlive a write
+	t.Run("suggest after a write", func(t *testing.T) {
+		query := domain.BookSuggestQuery{Prefix: "dun", Limit: 5}.Normalize()
+		if _, err := suggestRepo.SuggestBooks(ctx, query); err != nil {
+			t.Fatalf("SuggestBooks(%+v): %v", query, err)
+		}
+		if err := repo.UpdateBookByID(ctx, dune.ID, domain.Book{Title: "Dune Messiah"}); err != nil {
+			t.Fatalf("UpdateBookByID(%d): %v", dune.ID, err)
+		}
+		got, err := suggestRepo.SuggestBooks(ctx, query)
+		if err != nil {
+			t.Fatalf("SuggestBooks(%+v): %v", query, err)
+		}
+		assertSuggestions(t, got, title("Dune Messiah", 1))
+	})
+}
+
+// CreateBook creates a book in repo and returns it with its ID
+func CreateBook(t *testing.T, repo service.BookRepo, title, author string, year int) domain.Book {
+	t.Helper()
+	book := domain.Book{Title: title, Author: author, Year: year}
+	if err := repo.CreateBook(context.Background(), &book); err != nil {
+		t.Fatalf("CreateBook(%q, %q): %v", title, author, err)
+	}
+	return book
+}
+
+func deleteBook(t *testing.T, repo service.BookRepo, ID int) {
+	t.Helper()
+	if err := repo.DeleteBookByID(context.Background(), ID); err != nil {
+		t.Fatalf("DeleteBookByID(%d): %v", ID, err)
+	}
+}
+
+// assertBook checks that repo holds want under its ID
+func assertBook(t *testing.T, repo service.BookRepo, want domain.Book) {
+	t.Helper()
+	got, err := repo.GetBookByID(context.Background(), want.ID)
+	if err != nil {
+		t.Fatalf("GetBookByID(%d): %v", want.ID, err)
+	}
+	if *got != want {
+		t.Errorf("GetBookByID(%d) = %+v, want %+v", want.ID, *got, want)
+	}
+}
+
+func assertNotFound(t *testing.T, repo service.BookRepo, ID int) {
+	t.Helper()
+	if _, err := repo.GetBookByID(context.Background(), ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetBookByID(%d) returned %v, want gorm.ErrRecordNotFound", ID, err)
+	}
+}
+
+func assertBooks(t *testing.T, got []*domain.Book, want ...domain.Book) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d books %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("book %d = %+v, want %+v", i, *got[i], want[i])
+		}
+	}
+}
+
+func assertHits(t *testing.T, got []*domain.BookSearchHit, want ...domain.Book) {
+	t.Helper()
+	books := make([]*domain.Book, 0, len(got))
+	for _, hit := range got {
+		books = append(books, &hit.Book)
+	}
+	assertBooks(t, books, want...)
+}
+
+func assertFacets(t *testing.T, got, want *domain.BookFacets) {
+	t.Helper()
+	if got.Total != want.Total {
+		t.Errorf("total = %d, want %d", got.Total, want.Total)
+	}
+	if !slices.Equal(got.Authors, want.Authors) {
+		t.Errorf("authors = %v, want %v", got.Authors, want.Authors)
+	}
+	if !slices.Equal(got.Decades, want.Decades) {
+		t.Errorf("decades = %v, want %v", got.Decades, want.Decades)
+	}
+	if !slices.Equal(got.Years, want.Years) {
+		t.Errorf("years = %v, want %v", got.Years, want.Years)
+	}
+}
+
+func assertSuggestions(t *testing.T, got []*domain.BookSuggestion, want ...domain.BookSuggestion) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d suggestions %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("suggestion %d = %+v, want %+v", i, *got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package servicetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+// EventBus is a fake service.EventBus standing in for Kafka and Redis Streams.
+// It keeps every message published to it, and fails every Publish while FailWith has set an error.
+type EventBus struct {
+	mu        sync.Mutex
+	published []PublishedMessage
+	err       error
+}
+
+// PublishedMessage is a message handed to EventBus.Publish
+type PublishedMessage struct {
+	Topic   string
+	Message interface{}
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+func (b *EventBus) Publish(ctx context.Context, topic string, message interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	b.published = append(b.published, PublishedMessage{Topic: topic, Message: message})
+	return nil
+}
+
+// FailWith makes Publish return err, like an unreachable broker, until it is called again with nil
+func (b *EventBus) FailWith(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// Published returns the messages published so far, in order
+func (b *EventBus) Published() []PublishedMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]PublishedMessage(nil), b.published...)
+}
+
+// EventTypes returns the CloudEvents type of every published envelope, in order
+func (b *EventBus) EventTypes() []string {
+	var types []string
+	for _, published := range b.Published() {
+		if envelope, ok := published.Message.(*domain.CloudEvent); ok {
+			types = append(types, envelope.Type)
+		}
+	}
+	return types
+}
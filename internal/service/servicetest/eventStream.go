@@ -0,0 +1,80 @@
+package servicetest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Redarcher9/Books-Management-System/internal/domain"
+)
+
+// EventStream is a fake service.EventStream standing in for the Kafka topics that book events are read back from
+type EventStream struct {
+	mu         sync.Mutex
+	partitions map[string]map[int][]streamMessage
+}
+
+type streamMessage struct {
+	message    domain.StreamMessage
+	producedAt time.Time
+}
+
+func NewEventStream() *EventStream {
+	return &EventStream{
+		partitions: make(map[string]map[int][]streamMessage),
+	}
+}
+
+// Produce appends value to a partition of topic and returns its offset
+func (s *EventStream) Produce(topic string, partition int, value []byte) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.partitions[topic] == nil {
+		s.partitions[topic] = make(map[int][]streamMessage)
+	}
+	offset := int64(len(s.partitions[topic][partition]))
+	s.partitions[topic][partition] = append(s.partitions[topic][partition], streamMessage{
+		message: domain.StreamMessage{
+			Topic:     topic,
+			Partition: partition,
+			Offset:    offset,
+			Value:     value,
+		},
+		producedAt: time.Now(),
+	})
+	return offset
+}
+
+func (s *EventStream) Partitions(ctx context.Context, topic string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partitions := make([]int, 0, len(s.partitions[topic]))
+	for partition := range s.partitions[topic] {
+		partitions = append(partitions, partition)
+	}
+	sort.Ints(partitions)
+	return partitions, nil
+}
+
+// Read hands the messages of the partition from start to handle, from the first message when start is empty
+func (s *EventStream) Read(ctx context.Context, topic string, partition int, start domain.ReplayFrom, handle func(domain.StreamMessage) error) error {
+	s.mu.Lock()
+	messages := append([]streamMessage(nil), s.partitions[topic][partition]...)
+	s.mu.Unlock()
+
+	for _, m := range messages {
+		if start.Offset != nil && m.message.Offset < *start.Offset {
+			continue
+		}
+		if start.Time != nil && m.producedAt.Before(*start.Time) {
+			continue
+		}
+		if err := handle(m.message); err != nil {
+			return err
+		}
+	}
+	return nil
+}